@@ -0,0 +1,26 @@
+data "k0sctl_kubeconfig" "cluster" {
+  api_address = "k8s.example.org"
+
+  metadata {
+    name = "my-cluster"
+  }
+
+  spec {
+    host {
+      role = "controller"
+
+      ssh {
+        address  = "controller1.example.org"
+        user     = "ubuntu"
+        key_path = "~/.ssh/id_rsa"
+      }
+    }
+  }
+}
+
+provider "kubernetes" {
+  host                   = data.k0sctl_kubeconfig.cluster.kube_host
+  cluster_ca_certificate = data.k0sctl_kubeconfig.cluster.ca_cert
+  client_certificate     = data.k0sctl_kubeconfig.cluster.client_cert
+  client_key             = data.k0sctl_kubeconfig.cluster.private_key
+}
@@ -0,0 +1,35 @@
+ephemeral "k0sctl_kubeconfig" "cluster" {
+  api_address = "k8s.example.org"
+
+  metadata {
+    name = "my-cluster"
+  }
+
+  spec {
+    host {
+      role = "controller"
+
+      ssh {
+        address  = "controller1.example.org"
+        user     = "ubuntu"
+        key_path = "~/.ssh/id_rsa"
+      }
+    }
+  }
+}
+
+provider "kubernetes" {
+  host                   = ephemeral.k0sctl_kubeconfig.cluster.kube_host
+  cluster_ca_certificate = ephemeral.k0sctl_kubeconfig.cluster.ca_cert
+  client_certificate     = ephemeral.k0sctl_kubeconfig.cluster.client_cert
+  client_key             = ephemeral.k0sctl_kubeconfig.cluster.private_key
+}
+
+provider "helm" {
+  kubernetes {
+    host                   = ephemeral.k0sctl_kubeconfig.cluster.kube_host
+    cluster_ca_certificate = ephemeral.k0sctl_kubeconfig.cluster.ca_cert
+    client_certificate     = ephemeral.k0sctl_kubeconfig.cluster.client_cert
+    client_key             = ephemeral.k0sctl_kubeconfig.cluster.private_key
+  }
+}
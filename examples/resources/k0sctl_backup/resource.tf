@@ -0,0 +1,15 @@
+resource "k0sctl_backup" "pre_upgrade" {
+  destination_dir = "${path.module}/backups"
+
+  # take a new backup whenever the k0s version changes
+  triggers = {
+    k0s_version = k0sctl_config.cluster.spec.k0s.version
+  }
+
+  # connect to the hosts of the cluster managed by k0sctl_config
+  hosts = k0sctl_config.cluster.spec.host
+
+  timeouts {
+    create = "20m"
+  }
+}
@@ -0,0 +1,4 @@
+# The import id is the path to a k0sctl.yaml file describing an existing cluster.
+# The key_content, key_passphrase and use_agent ssh options are not part of k0sctl.yaml,
+# so they are not imported, set them in the configuration of the imported hosts.
+terraform import k0sctl_config.cluster ./k0sctl.yaml
@@ -0,0 +1,11 @@
+resource "k0sctl_kubeconfig_user" "ci" {
+  user   = "ci"
+  groups = ["ci-deployers"]
+
+  # connect to the hosts of the cluster managed by k0sctl_config
+  hosts = k0sctl_config.cluster.spec.host
+}
+
+output "ci_kubeconfig_expires_at" {
+  value = k0sctl_kubeconfig_user.ci.expires_at
+}
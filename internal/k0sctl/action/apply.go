@@ -1,6 +1,7 @@
 package action
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -17,6 +18,18 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// HostOperation the operation which an apply performs on a host.
+type HostOperation struct {
+	// Host is the host address
+	Host string
+	// Role is the host role in the cluster
+	Role string
+	// Operation is one of install, upgrade, reset or no-op
+	Operation string
+	// RunningVersion is the k0s version which was running on the host before the apply, if any
+	RunningVersion string
+}
+
 type Apply struct {
 	// Manager is the phase manager
 	Manager *phase.Manager
@@ -38,7 +51,8 @@ type Apply struct {
 	ConfigPath string
 }
 
-func (a Apply) Run() error {
+// Run the apply, cancelling it when the context is done.
+func (a Apply) Run(ctx context.Context) error {
 	start := time.Now()
 
 	phase.NoWait = a.NoWait
@@ -46,7 +60,7 @@ func (a Apply) Run() error {
 
 	lockPhase := &phase.Lock{}
 
-	a.Manager.AddPhase(
+	phases := []runner{
 		&phase.DefaultK0sVersion{},
 		&phase.Connect{},
 		&phase.DetectOS{},
@@ -81,22 +95,22 @@ func (a Apply) Run() error {
 		&phase.ResetControllers{NoDrain: a.NoDrain},
 		&provider_phase.ValidateHostsExtended{},
 		&phase.RunHooks{Stage: "after", Action: "apply"},
-	)
+	}
 
 	if a.KubeconfigOut != nil {
-		a.Manager.AddPhase(&phase.GetKubeconfig{APIAddress: a.KubeconfigAPIAddress})
+		phases = append(phases, &phase.GetKubeconfig{APIAddress: a.KubeconfigAPIAddress})
 	}
 
-	a.Manager.AddPhase(
+	final := []runner{
 		&phase.Unlock{Cancel: lockPhase.Cancel},
 		&phase.Disconnect{},
-	)
+	}
 
 	analytics.Client.Publish("apply-start", map[string]interface{}{})
 
 	var result error
 
-	if result = a.Manager.Run(); result != nil {
+	if result = runCancellable(ctx, a.Manager, phases, final); result != nil {
 		analytics.Client.Publish("apply-failure", map[string]interface{}{"clusterID": a.Manager.Config.Spec.K0s.Metadata.ClusterID})
 		log.Info(phase.Colorize.Red("==> Apply failed").String())
 		return result
@@ -150,3 +164,37 @@ func (a Apply) Run() error {
 
 	return nil
 }
+
+// Operations the operation performed on each host, as determined by the facts gathered during the run.
+//
+// After a dry run, these are the operations that an apply would perform.
+func (a Apply) Operations() []HostOperation {
+	hos := []HostOperation{}
+
+	for _, h := range a.Manager.Config.Spec.Hosts {
+		ho := HostOperation{
+			Host:      h.Address(),
+			Role:      h.Role,
+			Operation: "no-op",
+		}
+
+		if h.Metadata.K0sRunningVersion != nil {
+			ho.RunningVersion = h.Metadata.K0sRunningVersion.String()
+		}
+
+		switch {
+		case h.Reset && h.Metadata.K0sRunningVersion != nil:
+			ho.Operation = "reset"
+		case h.Reset:
+			// nothing to reset
+		case h.Metadata.K0sRunningVersion == nil:
+			ho.Operation = "install"
+		case h.Metadata.NeedsUpgrade:
+			ho.Operation = "upgrade"
+		}
+
+		hos = append(hos, ho)
+	}
+
+	return hos
+}
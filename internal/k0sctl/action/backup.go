@@ -0,0 +1,61 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/k0sproject/k0sctl/analytics"
+	"github.com/k0sproject/k0sctl/phase"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Backup takes a backup of the cluster state from the k0s leader.
+type Backup struct {
+	// Manager is the phase manager
+	Manager *phase.Manager
+	// Out is a writer to write the backup archive to
+	Out io.Writer
+}
+
+// Run the backup, cancelling it when the context is done.
+func (b Backup) Run(ctx context.Context) error {
+	start := time.Now()
+
+	lockPhase := &phase.Lock{}
+
+	phases := []runner{
+		&phase.Connect{},
+		&phase.DetectOS{},
+		lockPhase,
+		&phase.PrepareHosts{},
+		&phase.GatherFacts{SkipMachineIDs: true},
+		&phase.GatherK0sFacts{},
+		&phase.RunHooks{Stage: "before", Action: "backup"},
+		&phase.Backup{Out: b.Out},
+		&phase.RunHooks{Stage: "after", Action: "backup"},
+	}
+
+	final := []runner{
+		&phase.Unlock{Cancel: lockPhase.Cancel},
+		&phase.Disconnect{},
+	}
+
+	analytics.Client.Publish("backup-start", map[string]interface{}{})
+
+	if err := runCancellable(ctx, b.Manager, phases, final); err != nil {
+		analytics.Client.Publish("backup-failure", map[string]interface{}{"clusterID": b.Manager.Config.Spec.K0s.Metadata.ClusterID})
+		log.Info(phase.Colorize.Red("==> Backup failed").String())
+		return err
+	}
+
+	analytics.Client.Publish("backup-success", map[string]interface{}{"duration": time.Since(start), "clusterID": b.Manager.Config.Spec.K0s.Metadata.ClusterID})
+
+	duration := time.Since(start).Truncate(time.Second)
+	text := fmt.Sprintf("==> Finished in %s", duration)
+	log.Infof(phase.Colorize.Green(text).String())
+
+	return nil
+}
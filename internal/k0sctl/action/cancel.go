@@ -0,0 +1,378 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/k0sproject/k0sctl/phase"
+
+	k0sctl_v1beta1 "github.com/k0sproject/k0sctl/pkg/apis/k0sctl.k0sproject.io/v1beta1"
+	k0sctl_cluster "github.com/k0sproject/k0sctl/pkg/apis/k0sctl.k0sproject.io/v1beta1/cluster"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// runner a phase which can be added to the phase manager.
+type runner interface {
+	Title() string
+	Run() error
+}
+
+// CancelledError is returned when a run was cancelled, because it timed out or terraform was interrupted.
+type CancelledError struct {
+	// Phase is the title of the phase which was in progress, if any
+	Phase string
+	// Activity is the last logged activity of each host in the phase which was in progress
+	Activity map[string]string
+	// Err is the context error which caused the cancellation
+	Err error
+}
+
+func (e CancelledError) Error() string {
+	var sb strings.Builder
+
+	if errors.Is(e.Err, context.DeadlineExceeded) {
+		sb.WriteString("k0sctl timed out")
+	} else {
+		sb.WriteString("k0sctl was interrupted")
+	}
+
+	if e.Phase == "" {
+		sb.WriteString(" between phases")
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, " while running phase '%s'", e.Phase)
+
+	hosts := make([]string, 0, len(e.Activity))
+	for h := range e.Activity {
+		hosts = append(hosts, h)
+	}
+	sort.Strings(hosts)
+
+	for _, h := range hosts {
+		fmt.Fprintf(&sb, "\n - %s: last activity '%s'", h, e.Activity[h])
+	}
+
+	return sb.String()
+}
+
+func (e CancelledError) Unwrap() error {
+	return e.Err
+}
+
+// cancelWaitWarnPeriod how often a warning is logged while a cancelled phase is waited for.
+var cancelWaitWarnPeriod = 2 * time.Minute
+
+// runCancellable run phases with the phase manager, stopping the run when the context is done.
+//
+// When the context is done, the connections of the hosts are interrupted so that the remote commands of the running
+// phase fail, and the phase is waited for, so that it no longer changes the hosts. The interrupted hosts are then
+// reconnected, and the final phases (unlock and disconnect) are run so that the hosts are released.
+func runCancellable(ctx context.Context, m *phase.Manager, phases []runner, final []runner) error {
+	t := newPhaseTracker(m.Config.Spec.Hosts)
+	defer t.close()
+
+	cps := make([]*cancellablePhase, 0, len(phases))
+	for _, p := range phases {
+		cp := &cancellablePhase{
+			runner:  p,
+			ctx:     ctx,
+			tracker: t,
+			warn:    cancelWaitWarnPeriod,
+			interrupt: func() k0sctl_cluster.Hosts {
+				return interruptConnections(m.Config.Spec.Hosts)
+			},
+		}
+		cps = append(cps, cp)
+		m.AddPhase(cp)
+	}
+	for _, p := range final {
+		m.AddPhase(p)
+	}
+
+	err := m.Run()
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+
+	ce := t.cancelledError(ctx.Err())
+
+	interrupted := k0sctl_cluster.Hosts{}
+	for _, cp := range cps {
+		interrupted = append(interrupted, cp.interrupted...)
+	}
+
+	log.Warnf("k0sctl run was cancelled, running the final phases to release the hosts")
+
+	for _, h := range interrupted {
+		if err := h.Connect(); err != nil {
+			log.Warnf("%s: could not reconnect to release the host: %s", h, err)
+		}
+	}
+
+	if fm, ferr := phase.NewManager(m.Config); ferr != nil {
+		log.Errorf("could not create a phase manager to release the hosts: %s", ferr)
+	} else {
+		for _, p := range final {
+			fm.AddPhase(p)
+		}
+		if ferr := fm.Run(); ferr != nil {
+			log.Errorf("could not release the hosts after cancellation: %s", ferr)
+		}
+	}
+
+	return ce
+}
+
+// interruptConnections disconnect the ssh and openssh hosts, so that their remote commands in progress fail.
+//
+// winrm and localhost commands can't be interrupted, and are left to finish. The interrupted hosts are returned so
+// that they can be reconnected.
+func interruptConnections(hosts k0sctl_cluster.Hosts) k0sctl_cluster.Hosts {
+	interrupted := k0sctl_cluster.Hosts{}
+
+	for _, h := range hosts {
+		if !h.IsConnected() {
+			continue
+		}
+
+		switch {
+		case h.WinRM != nil, h.Localhost != nil:
+			continue
+		case h.SSH != nil:
+			h.SSH.Disconnect()
+		case h.OpenSSH != nil:
+			h.OpenSSH.Disconnect()
+		default:
+			continue
+		}
+
+		log.Warnf("%s: interrupted the connection to cancel the running phase", h)
+		interrupted = append(interrupted, h)
+	}
+
+	return interrupted
+}
+
+// cancellablePhase wraps a phase so that it is interrupted when the context is done.
+//
+// The optional phase manager interfaces are passed through to the wrapped phase.
+type cancellablePhase struct {
+	runner
+	ctx     context.Context
+	tracker *phaseTracker
+
+	// warn is how often a warning is logged while the interrupted phase is waited for
+	warn time.Duration
+	// interrupt interrupts the phase in progress, and returns the hosts which were interrupted
+	interrupt func() k0sctl_cluster.Hosts
+
+	// interrupted are the hosts which were interrupted to cancel the phase
+	interrupted k0sctl_cluster.Hosts
+}
+
+func (p *cancellablePhase) Prepare(c *k0sctl_v1beta1.Cluster) error {
+	if pp, ok := p.runner.(interface {
+		Prepare(*k0sctl_v1beta1.Cluster) error
+	}); ok {
+		return pp.Prepare(c)
+	}
+	return nil
+}
+
+func (p *cancellablePhase) SetManager(m *phase.Manager) {
+	if pp, ok := p.runner.(interface{ SetManager(*phase.Manager) }); ok {
+		pp.SetManager(m)
+	}
+}
+
+func (p *cancellablePhase) ShouldRun() bool {
+	if pp, ok := p.runner.(interface{ ShouldRun() bool }); ok {
+		return pp.ShouldRun()
+	}
+	return true
+}
+
+func (p *cancellablePhase) CleanUp() {
+	if pp, ok := p.runner.(interface{ CleanUp() }); ok {
+		pp.CleanUp()
+	}
+}
+
+func (p *cancellablePhase) Run() error {
+	return p.run(p.runner.Run)
+}
+
+func (p *cancellablePhase) DryRun() error {
+	if pp, ok := p.runner.(interface{ DryRun() error }); ok {
+		return p.run(pp.DryRun)
+	}
+	return p.run(p.runner.Run)
+}
+
+func (p *cancellablePhase) run(f func() error) error {
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+
+	p.tracker.start(p.Title())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- f()
+	}()
+
+	select {
+	case err := <-done:
+		p.tracker.finish()
+		return err
+	case <-p.ctx.Done():
+	}
+
+	if p.interrupt != nil {
+		p.interrupted = p.interrupt()
+	}
+
+	// the final phases can only run once the phase no longer changes the hosts, e.g. after a winrm command ended
+	tk := time.NewTicker(p.warn)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-done:
+			return p.ctx.Err()
+		case <-tk.C:
+			log.Warnf("waiting for the cancelled phase '%s' to stop before releasing the hosts", p.Title())
+		}
+	}
+}
+
+// phaseTracker records the phase in progress, and the last logged activity of each host in it.
+//
+// k0sctl phases log host activity as "host: message" to the global logger, so the activity is collected from the
+// logs of all of the runs. An activity is only recorded by the run which tracks the host, and not at all if several
+// runs in progress track the same host, as it can't be told which run logged it.
+type phaseTracker struct {
+	mu       sync.Mutex
+	hosts    []string
+	phase    string
+	activity map[string]string
+}
+
+var (
+	phaseTrackersMu   sync.Mutex
+	phaseTrackers     = map[*phaseTracker]struct{}{}
+	phaseTrackersOnce sync.Once
+)
+
+func newPhaseTracker(hosts k0sctl_cluster.Hosts) *phaseTracker {
+	t := &phaseTracker{
+		activity: map[string]string{},
+	}
+	for _, h := range hosts {
+		t.hosts = append(t.hosts, h.String())
+	}
+
+	phaseTrackersOnce.Do(func() {
+		log.AddHook(phaseTrackerHook{})
+	})
+
+	phaseTrackersMu.Lock()
+	phaseTrackers[t] = struct{}{}
+	phaseTrackersMu.Unlock()
+
+	return t
+}
+
+func (t *phaseTracker) close() {
+	phaseTrackersMu.Lock()
+	delete(phaseTrackers, t)
+	phaseTrackersMu.Unlock()
+}
+
+func (t *phaseTracker) start(title string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phase = title
+	t.activity = map[string]string{}
+}
+
+func (t *phaseTracker) finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phase = ""
+}
+
+// host the tracked host which a message is logged for, empty if the message is not about a tracked host.
+func (t *phaseTracker) host(mes string) string {
+	for _, h := range t.hosts {
+		if strings.HasPrefix(mes, h+":") {
+			return h
+		}
+	}
+	return ""
+}
+
+func (t *phaseTracker) observe(h, mes string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.phase == "" {
+		return
+	}
+
+	t.activity[h] = strings.TrimSpace(strings.TrimPrefix(mes, h+":"))
+}
+
+func (t *phaseTracker) cancelledError(err error) CancelledError {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ce := CancelledError{
+		Phase:    t.phase,
+		Activity: map[string]string{},
+		Err:      err,
+	}
+	for h, a := range t.activity {
+		ce.Activity[h] = a
+	}
+	return ce
+}
+
+// phaseTrackerHook passes logrus entries to the phase tracker of the run which tracks the host they are about.
+type phaseTrackerHook struct{}
+
+func (h phaseTrackerHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h phaseTrackerHook) Fire(e *log.Entry) error {
+	phaseTrackersMu.Lock()
+	defer phaseTrackersMu.Unlock()
+
+	var owner *phaseTracker
+	var host string
+
+	for t := range phaseTrackers {
+		th := t.host(e.Message)
+		if th == "" {
+			continue
+		}
+		if owner != nil {
+			// several runs track the host
+			return nil
+		}
+		owner, host = t, th
+	}
+
+	if owner != nil {
+		owner.observe(host, e.Message)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	k0sctl_cluster "github.com/k0sproject/k0sctl/pkg/apis/k0sctl.k0sproject.io/v1beta1/cluster"
+	"github.com/k0sproject/rig"
+	log "github.com/sirupsen/logrus"
+)
+
+// blockingPhase a phase which runs until it is released.
+type blockingPhase struct {
+	release chan struct{}
+	err     error
+}
+
+func (p *blockingPhase) Title() string {
+	return "Blocking phase"
+}
+
+func (p *blockingPhase) Run() error {
+	<-p.release
+	return p.err
+}
+
+func TestCancellablePhase(t *testing.T) {
+	h := &k0sctl_cluster.Host{Connection: rig.Connection{SSH: &rig.SSH{Address: "10.0.0.1"}}}
+
+	t.Run("finished", func(t *testing.T) {
+		tracker := newPhaseTracker(k0sctl_cluster.Hosts{h})
+		defer tracker.close()
+
+		bp := &blockingPhase{release: make(chan struct{}), err: errors.New("phase failed")}
+		close(bp.release)
+
+		cp := &cancellablePhase{runner: bp, ctx: context.Background(), tracker: tracker, warn: time.Second}
+		if err := cp.Run(); err != bp.err {
+			t.Errorf("the phase error was not returned, got: %v", err)
+		}
+		if ce := tracker.cancelledError(nil); ce.Phase != "" {
+			t.Errorf("the finished phase is still in progress: %s", ce.Phase)
+		}
+	})
+
+	t.Run("interrupted", func(t *testing.T) {
+		tracker := newPhaseTracker(k0sctl_cluster.Hosts{h})
+		defer tracker.close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		bp := &blockingPhase{release: make(chan struct{})}
+
+		cp := &cancellablePhase{
+			runner:  bp,
+			ctx:     ctx,
+			tracker: tracker,
+			warn:    time.Minute,
+			interrupt: func() k0sctl_cluster.Hosts {
+				// interrupting the connections makes the phase fail
+				close(bp.release)
+				return k0sctl_cluster.Hosts{h}
+			},
+		}
+
+		go func() {
+			log.Infof("%s: running a command", h)
+			cancel()
+		}()
+
+		if err := cp.Run(); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the context error, got: %v", err)
+		}
+		if len(cp.interrupted) != 1 || cp.interrupted[0] != h {
+			t.Errorf("the interrupted hosts were not recorded: %v", cp.interrupted)
+		}
+
+		ce := tracker.cancelledError(ctx.Err())
+		if ce.Phase != bp.Title() {
+			t.Errorf("unexpected phase in progress: %s", ce.Phase)
+		}
+		if a := ce.Activity[h.String()]; a != "running a command" {
+			t.Errorf("unexpected host activity: %s", a)
+		}
+	})
+
+	t.Run("waited", func(t *testing.T) {
+		tracker := newPhaseTracker(k0sctl_cluster.Hosts{h})
+		defer tracker.close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		bp := &blockingPhase{release: make(chan struct{})}
+
+		cp := &cancellablePhase{runner: bp, ctx: ctx, tracker: tracker, warn: 10 * time.Millisecond}
+
+		if err := cp.Run(); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the context error, got: %v", err)
+		}
+
+		// a phase which is not stopped by the interruption is waited for, so that it no longer changes the hosts
+		released := false
+		cp.ctx, cancel = context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		time.AfterFunc(50*time.Millisecond, func() {
+			released = true
+			close(bp.release)
+		})
+
+		if err := cp.Run(); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the context error, got: %v", err)
+		}
+		if !released {
+			t.Error("the cancelled phase was not waited for")
+		}
+	})
+}
+
+func TestPhaseTrackerHook(t *testing.T) {
+	h1 := &k0sctl_cluster.Host{Connection: rig.Connection{SSH: &rig.SSH{Address: "10.0.0.1", Port: 22}}}
+	h2 := &k0sctl_cluster.Host{Connection: rig.Connection{SSH: &rig.SSH{Address: "10.0.0.2", Port: 22}}}
+
+	t1 := newPhaseTracker(k0sctl_cluster.Hosts{h1})
+	defer t1.close()
+	t2 := newPhaseTracker(k0sctl_cluster.Hosts{h1, h2})
+	defer t2.close()
+
+	t1.start("Upgrade controllers")
+	t2.start("Upgrade workers")
+
+	log.Infof("%s: draining", h2)
+	log.Infof("%s: upgrading", h1)
+
+	if a := t1.cancelledError(nil).Activity; len(a) != 0 {
+		t.Errorf("activity of another run or of a shared host was recorded: %v", a)
+	}
+	if a := t2.cancelledError(nil).Activity; len(a) != 1 || a[h2.String()] != "draining" {
+		t.Errorf("only the activity of the host of the run was expected, got: %v", a)
+	}
+}
+
+func TestInterruptConnections(t *testing.T) {
+	localhost := &k0sctl_cluster.Host{Connection: rig.Connection{Localhost: &rig.Localhost{Enabled: true}}}
+	if err := localhost.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer localhost.Disconnect()
+
+	disconnected := &k0sctl_cluster.Host{Connection: rig.Connection{SSH: &rig.SSH{Address: "10.0.0.1"}}}
+
+	if interrupted := interruptConnections(k0sctl_cluster.Hosts{localhost, disconnected}); len(interrupted) != 0 {
+		t.Errorf("only connected ssh hosts should be interrupted, got: %v", interrupted)
+	}
+	if !localhost.IsConnected() {
+		t.Error("the localhost connection was interrupted")
+	}
+}
+
+func TestCancelledError(t *testing.T) {
+	ce := CancelledError{
+		Phase: "Upgrade workers",
+		Activity: map[string]string{
+			"[ssh] 10.0.0.2:22": "draining",
+			"[ssh] 10.0.0.1:22": "waiting for the node to become ready",
+		},
+		Err: context.DeadlineExceeded,
+	}
+
+	expected := `k0sctl timed out while running phase 'Upgrade workers'
+ - [ssh] 10.0.0.1:22: last activity 'waiting for the node to become ready'
+ - [ssh] 10.0.0.2:22: last activity 'draining'`
+	if ce.Error() != expected {
+		t.Errorf("unexpected error message, got:\n%s\nexpected:\n%s", ce.Error(), expected)
+	}
+	if !errors.Is(ce, context.DeadlineExceeded) {
+		t.Error("the context error is not unwrapped")
+	}
+
+	ce = CancelledError{Err: context.Canceled}
+	if ce.Error() != "k0sctl was interrupted between phases" {
+		t.Errorf("unexpected error message: %s", ce.Error())
+	}
+}
@@ -0,0 +1,56 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/k0sproject/k0sctl/phase"
+
+	provider_phase "github.com/mirantis/terraform-provider-k0sctl/internal/k0sctl/phase"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Kubeconfig fetches the admin kubeconfig from the controllers of a running cluster, without altering the hosts.
+type Kubeconfig struct {
+	// Manager is the phase manager
+	Manager *phase.Manager
+	// APIAddress overrides the API address in the kubeconfig, if set
+	APIAddress string
+	// Out is a writer to write the kubeconfig to
+	Out io.Writer
+}
+
+// Run the kubeconfig retrieval, cancelling it when the context is done.
+func (a Kubeconfig) Run(ctx context.Context) error {
+	kubeconfigPhase := &provider_phase.GetKubeconfig{}
+	kubeconfigPhase.APIAddress = a.APIAddress
+
+	phases := []runner{
+		&phase.Connect{},
+		&phase.DetectOS{},
+		&phase.GatherFacts{SkipMachineIDs: true},
+		&provider_phase.GatherK0sState{},
+		kubeconfigPhase,
+	}
+
+	final := []runner{
+		&phase.Disconnect{},
+	}
+
+	if err := runCancellable(ctx, a.Manager, phases, final); err != nil {
+		log.Info(phase.Colorize.Red("==> Kubeconfig retrieval failed").String())
+		return err
+	}
+
+	if a.Manager.Config.Metadata.Kubeconfig == "" {
+		return fmt.Errorf("k0s is not running on any of the controllers")
+	}
+
+	if _, err := a.Out.Write([]byte(a.Manager.Config.Metadata.Kubeconfig)); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+
+	return nil
+}
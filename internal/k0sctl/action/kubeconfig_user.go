@@ -0,0 +1,56 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/k0sproject/k0sctl/phase"
+
+	provider_phase "github.com/mirantis/terraform-provider-k0sctl/internal/k0sctl/phase"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// KubeconfigUser creates a kubeconfig for a named user on the k0s leader, without otherwise altering the hosts.
+type KubeconfigUser struct {
+	// Manager is the phase manager
+	Manager *phase.Manager
+	// User is the name of the user
+	User string
+	// Groups are the groups of the user
+	Groups []string
+	// Out is a writer to write the kubeconfig to
+	Out io.Writer
+}
+
+// Run the kubeconfig creation, cancelling it when the context is done.
+func (a KubeconfigUser) Run(ctx context.Context) error {
+	createPhase := &provider_phase.CreateKubeconfig{
+		User:   a.User,
+		Groups: a.Groups,
+	}
+
+	phases := []runner{
+		&phase.Connect{},
+		&phase.DetectOS{},
+		&phase.GatherFacts{SkipMachineIDs: true},
+		&provider_phase.GatherK0sState{},
+		createPhase,
+	}
+
+	final := []runner{
+		&phase.Disconnect{},
+	}
+
+	if err := runCancellable(ctx, a.Manager, phases, final); err != nil {
+		log.Info(phase.Colorize.Red("==> Kubeconfig creation failed").String())
+		return err
+	}
+
+	if _, err := a.Out.Write([]byte(createPhase.Kubeconfig)); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+
+	return nil
+}
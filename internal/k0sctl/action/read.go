@@ -0,0 +1,78 @@
+package action
+
+import (
+	"context"
+	"io"
+
+	"github.com/k0sproject/k0sctl/phase"
+
+	k0sctl_cluster "github.com/k0sproject/k0sctl/pkg/apis/k0sctl.k0sproject.io/v1beta1/cluster"
+
+	provider_phase "github.com/mirantis/terraform-provider-k0sctl/internal/k0sctl/phase"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Read discovers what is running on the cluster hosts, without altering them.
+type Read struct {
+	// Manager is the phase manager
+	Manager *phase.Manager
+	// KubeconfigOut is a writer to write the kubeconfig to, if k0s is running on a controller
+	KubeconfigOut io.Writer
+	// KubeconfigAPIAddress is the API address to use in the kubeconfig
+	KubeconfigAPIAddress string
+	// States is populated with the discovered k0s state of each host after Run
+	States map[*k0sctl_cluster.Host]provider_phase.K0sHostState
+}
+
+// Run the read, cancelling it when the context is done.
+//
+// Hosts which can't be connected to are left out of the read, and reported in States as unreachable.
+func (a *Read) Run(ctx context.Context) error {
+	connectPhase := &provider_phase.ConnectReachable{}
+	statePhase := &provider_phase.GatherK0sState{}
+
+	// the connect phase leaves the unreachable hosts out of the config, the caller keeps using all of them
+	hosts := a.Manager.Config.Spec.Hosts
+	defer func() {
+		a.Manager.Config.Spec.Hosts = hosts
+	}()
+
+	phases := []runner{
+		connectPhase,
+		&phase.DetectOS{},
+		&phase.GatherFacts{},
+		statePhase,
+	}
+
+	if a.KubeconfigOut != nil {
+		kubeconfigPhase := &provider_phase.GetKubeconfig{}
+		kubeconfigPhase.APIAddress = a.KubeconfigAPIAddress
+		phases = append(phases, kubeconfigPhase)
+	}
+
+	final := []runner{
+		&phase.Disconnect{},
+	}
+
+	if err := runCancellable(ctx, a.Manager, phases, final); err != nil {
+		log.Info(phase.Colorize.Red("==> Read failed").String())
+		return err
+	}
+
+	a.States = statePhase.States
+	if a.States == nil {
+		a.States = map[*k0sctl_cluster.Host]provider_phase.K0sHostState{}
+	}
+	for h, err := range connectPhase.Unreachable {
+		a.States[h] = provider_phase.K0sHostState{Unreachable: err}
+	}
+
+	if a.KubeconfigOut != nil && a.Manager.Config.Metadata.Kubeconfig != "" {
+		if _, err := a.KubeconfigOut.Write([]byte(a.Manager.Config.Metadata.Kubeconfig)); err != nil {
+			log.Warnf("failed to write kubeconfig to %s: %v", a.KubeconfigOut, err)
+		}
+	}
+
+	return nil
+}
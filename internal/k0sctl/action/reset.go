@@ -0,0 +1,69 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/k0sproject/k0sctl/analytics"
+	"github.com/k0sproject/k0sctl/phase"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Reset uninstalls k0s from all of the cluster hosts.
+//
+// Unlike the k0sctl reset action it never asks for confirmation, and it can be cancelled.
+type Reset struct {
+	// Manager is the phase manager
+	Manager *phase.Manager
+}
+
+// Run the reset, cancelling it when the context is done.
+func (r Reset) Run(ctx context.Context) error {
+	start := time.Now()
+
+	lockPhase := &phase.Lock{}
+
+	phases := []runner{
+		&phase.Connect{},
+		&phase.DetectOS{},
+		lockPhase,
+		&phase.PrepareHosts{},
+		&phase.GatherFacts{SkipMachineIDs: true},
+		&phase.GatherK0sFacts{},
+		&phase.RunHooks{Stage: "before", Action: "reset"},
+		&phase.ResetWorkers{
+			NoDrain:  true,
+			NoDelete: true,
+		},
+		&phase.ResetControllers{
+			NoDrain:  true,
+			NoLeave:  true,
+			NoDelete: true,
+		},
+		&phase.ResetLeader{},
+		&phase.RunHooks{Stage: "after", Action: "reset"},
+	}
+
+	final := []runner{
+		&phase.Unlock{Cancel: lockPhase.Cancel},
+		&phase.Disconnect{},
+	}
+
+	analytics.Client.Publish("reset-start", map[string]interface{}{})
+
+	if err := runCancellable(ctx, r.Manager, phases, final); err != nil {
+		analytics.Client.Publish("reset-failure", map[string]interface{}{"clusterID": r.Manager.Config.Spec.K0s.Metadata.ClusterID})
+		log.Info(phase.Colorize.Red("==> Reset failed").String())
+		return err
+	}
+
+	analytics.Client.Publish("reset-success", map[string]interface{}{"duration": time.Since(start), "clusterID": r.Manager.Config.Spec.K0s.Metadata.ClusterID})
+
+	duration := time.Since(start).Truncate(time.Second)
+	text := fmt.Sprintf("==> Finished in %s", duration)
+	log.Infof(phase.Colorize.Green(text).String())
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package phase
+
+import (
+	"sync"
+
+	k0sctl_phase "github.com/k0sproject/k0sctl/phase"
+
+	k0sctl_cluster "github.com/k0sproject/k0sctl/pkg/apis/k0sctl.k0sproject.io/v1beta1/cluster"
+	"github.com/sirupsen/logrus"
+)
+
+// ConnectReachable connects to the hosts, and leaves the hosts which can't be connected to out of the following
+// phases.
+//
+// Unlike the k0sctl Connect phase, an unreachable host is not an error, and the connection is not retried, so
+// that reading a cluster with a host which is down reports the host instead of failing.
+type ConnectReachable struct {
+	k0sctl_phase.GenericPhase
+
+	// Unreachable is populated with the connection error of each host which could not be connected to
+	Unreachable map[*k0sctl_cluster.Host]error
+}
+
+// Title for the phase.
+func (p *ConnectReachable) Title() string {
+	return "Connect to reachable hosts"
+}
+
+// Run the phase.
+func (p *ConnectReachable) Run() error {
+	p.Unreachable = map[*k0sctl_cluster.Host]error{}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, h := range p.Config.Spec.Hosts {
+		wg.Add(1)
+		go func(h *k0sctl_cluster.Host) {
+			defer wg.Done()
+
+			if err := h.Connect(); err != nil {
+				logrus.Warnf("%s: could not connect, the host is left out: %s", h, err)
+
+				mu.Lock()
+				p.Unreachable[h] = err
+				mu.Unlock()
+				return
+			}
+
+			logrus.Infof("%s: connected", h)
+		}(h)
+	}
+
+	wg.Wait()
+
+	reachable := k0sctl_cluster.Hosts{}
+	for _, h := range p.Config.Spec.Hosts {
+		if _, ok := p.Unreachable[h]; !ok {
+			reachable = append(reachable, h)
+		}
+	}
+	p.Config.Spec.Hosts = reachable
+
+	logrus.Debug("ConnectReachable phase ran successfully")
+	return nil
+}
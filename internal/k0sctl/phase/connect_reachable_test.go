@@ -0,0 +1,51 @@
+package phase
+
+import (
+	"net"
+	"testing"
+
+	k0sctl_v1beta1 "github.com/k0sproject/k0sctl/pkg/apis/k0sctl.k0sproject.io/v1beta1"
+	k0sctl_cluster "github.com/k0sproject/k0sctl/pkg/apis/k0sctl.k0sproject.io/v1beta1/cluster"
+	"github.com/k0sproject/rig"
+)
+
+func TestConnectReachable(t *testing.T) {
+	// a port which nothing listens on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	reachable := &k0sctl_cluster.Host{
+		Role:       "controller",
+		Connection: rig.Connection{Localhost: &rig.Localhost{Enabled: true}},
+	}
+	unreachable := &k0sctl_cluster.Host{
+		Role:       "worker",
+		Connection: rig.Connection{SSH: &rig.SSH{Address: "127.0.0.1", Port: port, User: "root"}},
+	}
+
+	c := &k0sctl_v1beta1.Cluster{
+		Spec: &k0sctl_cluster.Spec{
+			Hosts: k0sctl_cluster.Hosts{reachable, unreachable},
+		},
+	}
+
+	p := &ConnectReachable{}
+	if err := p.Prepare(c); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Run(); err != nil {
+		t.Fatalf("an unreachable host failed the phase: %s", err)
+	}
+	defer reachable.Disconnect()
+
+	if len(c.Spec.Hosts) != 1 || c.Spec.Hosts[0] != reachable {
+		t.Errorf("only the reachable host should be left in the config, got %v", c.Spec.Hosts)
+	}
+	if _, ok := p.Unreachable[unreachable]; !ok || len(p.Unreachable) != 1 {
+		t.Errorf("only the unreachable host should be reported, got %v", p.Unreachable)
+	}
+}
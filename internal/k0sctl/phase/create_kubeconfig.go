@@ -0,0 +1,57 @@
+package phase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alessio/shellescape"
+	k0sctl_phase "github.com/k0sproject/k0sctl/phase"
+
+	"github.com/k0sproject/rig/exec"
+	"github.com/sirupsen/logrus"
+)
+
+// CreateKubeconfig creates a kubeconfig for a named user on the k0s leader, with `k0s kubeconfig create`.
+//
+// The client certificate is signed by the cluster CA, so the kubeconfig stays valid until the certificate
+// expires, there is no way to revoke it.
+type CreateKubeconfig struct {
+	k0sctl_phase.GenericPhase
+
+	// User is the name of the user, the common name of the client certificate
+	User string
+	// Groups are the groups of the user, the organizations of the client certificate
+	Groups []string
+
+	// Kubeconfig is populated with the created kubeconfig
+	Kubeconfig string
+}
+
+// Title for the phase.
+func (p *CreateKubeconfig) Title() string {
+	return "Create user kubeconfig"
+}
+
+// Run the phase.
+func (p *CreateKubeconfig) Run() error {
+	h := p.Config.Spec.K0sLeader()
+	if h == nil || h.Metadata.K0sRunningVersion == nil {
+		return fmt.Errorf("k0s is not running on the leader controller")
+	}
+
+	args := []string{"kubeconfig", "create"}
+	if len(p.Groups) > 0 {
+		args = append(args, "--groups", shellescape.Quote(strings.Join(p.Groups, ",")))
+	}
+	args = append(args, shellescape.Quote(p.User))
+
+	output, err := h.ExecOutput(h.Configurer.K0sCmdf(strings.Join(args, " ")), exec.Sudo(h), exec.HideOutput())
+	if err != nil {
+		return fmt.Errorf("%s: failed to create kubeconfig for user '%s': %w", h, p.User, err)
+	}
+
+	p.Kubeconfig = output
+
+	logrus.Debugf("%s: created kubeconfig for user '%s'", h, p.User)
+	return nil
+}
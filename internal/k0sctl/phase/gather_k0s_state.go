@@ -0,0 +1,95 @@
+package phase
+
+import (
+	"encoding/json"
+
+	k0sctl_phase "github.com/k0sproject/k0sctl/phase"
+
+	k0sctl_cluster "github.com/k0sproject/k0sctl/pkg/apis/k0sctl.k0sproject.io/v1beta1/cluster"
+	"github.com/k0sproject/rig/exec"
+	k0sversion "github.com/k0sproject/version"
+	"github.com/sirupsen/logrus"
+)
+
+// K0sHostState what k0s is actually running on a host.
+type K0sHostState struct {
+	// Running is false if k0s is not installed or not running on the host
+	Running bool
+	// Version is the running k0s version
+	Version *k0sversion.Version
+	// Role is the k0sctl role of the running k0s (controller, worker, controller+worker, single)
+	Role string
+	// Unreachable is the connection error if the host could not be connected to, nothing is known about it then
+	Unreachable error
+}
+
+// GatherK0sState collects the running k0s state for each host, without validating it against the configuration.
+//
+// Unlike the k0sctl GatherK0sFacts phase, a role or version mismatch is not an error, it is recorded so that
+// the caller can report drift.
+type GatherK0sState struct {
+	k0sctl_phase.GenericPhase
+
+	// States is populated with the discovered state of each host
+	States map[*k0sctl_cluster.Host]K0sHostState
+}
+
+// Title for the phase.
+func (p *GatherK0sState) Title() string {
+	return "Gather k0s state"
+}
+
+// Run the phase.
+func (p *GatherK0sState) Run() error {
+	p.States = map[*k0sctl_cluster.Host]K0sHostState{}
+
+	for _, h := range p.Config.Spec.Hosts {
+		p.States[h] = p.investigateK0s(h)
+	}
+
+	logrus.Debug("GatherK0sState phase ran successfully")
+	return nil
+}
+
+func (p *GatherK0sState) investigateK0s(h *k0sctl_cluster.Host) K0sHostState {
+	var status struct {
+		Version    string `json:"Version"`
+		Role       string `json:"Role"`
+		Workloads  bool   `json:"Workloads"`
+		SingleNode bool   `json:"SingleNode"`
+	}
+
+	output, err := h.ExecOutput(h.Configurer.K0sCmdf("status -o json"), exec.Sudo(h))
+	if err != nil {
+		logrus.Debugf("%s: no 'k0s status' output, k0s is not running: %s", h, err)
+		return K0sHostState{}
+	}
+
+	if err := json.Unmarshal([]byte(output), &status); err != nil {
+		logrus.Warnf("%s: could not interpret 'k0s status' output: %s", h, err)
+		return K0sHostState{}
+	}
+
+	s := K0sHostState{
+		Running: true,
+		Role:    status.Role,
+	}
+
+	switch {
+	case status.SingleNode:
+		s.Role = "single"
+	case status.Role == "controller" && status.Workloads:
+		s.Role = "controller+worker"
+	}
+
+	if v, err := k0sversion.NewVersion(status.Version); err != nil {
+		logrus.Warnf("%s: could not interpret running k0s version '%s': %s", h, status.Version, err)
+	} else {
+		s.Version = v
+		h.Metadata.K0sRunningVersion = v
+	}
+
+	logrus.Debugf("%s: k0s %s is running as %s", h, status.Version, s.Role)
+
+	return s
+}
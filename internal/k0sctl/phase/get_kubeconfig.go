@@ -0,0 +1,23 @@
+package phase
+
+import (
+	k0sctl_phase "github.com/k0sproject/k0sctl/phase"
+)
+
+// GetKubeconfig fetches the admin kubeconfig, but only if k0s was found running on a controller.
+//
+// The k0sctl GetKubeconfig phase assumes that k0s is installed, which is not the case when reading a
+// cluster that has drifted away from its configuration.
+type GetKubeconfig struct {
+	k0sctl_phase.GetKubeconfig
+}
+
+// ShouldRun is true if k0s is running on at least one controller.
+func (p *GetKubeconfig) ShouldRun() bool {
+	for _, h := range p.Config.Spec.Hosts.Controllers() {
+		if h.Metadata.K0sRunningVersion != nil {
+			return true
+		}
+	}
+	return false
+}
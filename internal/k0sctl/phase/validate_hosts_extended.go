@@ -26,6 +26,33 @@ func (p *ValidateHostsExtended) Run() error {
 	return p.validateWorkerCount()
 }
 
+// DryRun the phase, which only reports the nodes that would be deleted.
+func (p *ValidateHostsExtended) DryRun() error {
+	configWorkerMachineIDs, err := p.getWorkerMachineIDs()
+	if err != nil {
+		return err
+	}
+
+	nodeNames, leader, err := p.getNodeNamesAndLeader()
+	if err != nil {
+		return err
+	}
+
+	for _, node := range nodeNames {
+		machineID, err := p.getNodeMachineID(node, leader)
+		if err != nil {
+			logrus.Errorf("Error occurred while validating node: %s", err)
+			continue
+		}
+
+		if !slices.Contains(configWorkerMachineIDs, machineID) {
+			logrus.Infof("dry-run: node %s would be deleted", node)
+		}
+	}
+
+	return nil
+}
+
 func (p *ValidateHostsExtended) validateWorkerCount() error {
 
 	configWorkerMachineIDs, err := p.getWorkerMachineIDs()
@@ -57,6 +84,11 @@ func (p *ValidateHostsExtended) getWorkerMachineIDs() ([]string, error) {
 	var configWorkerMachineIDs []string
 
 	for _, h := range p.Config.Spec.Hosts.Workers() {
+		if h.Reset {
+			// hosts being reset are leaving the cluster
+			continue
+		}
+
 		id, err := h.Configurer.MachineID(h)
 		if err != nil {
 			return nil, err
@@ -90,7 +122,7 @@ func (p *ValidateHostsExtended) getNodeNamesAndLeader() ([]string, *k0sctl_clust
 }
 
 func (p *ValidateHostsExtended) validateAndDeleteNode(node string, configWorkerMachineIDs []string, leader *k0sctl_cluster.Host) error {
-	machineID, err := leader.ExecOutput(leader.Configurer.KubectlCmdf(leader, leader.K0sDataDir(), fmt.Sprintf("describe node %s | grep -i 'Machine ID:' | awk '{print $3}'", node)), exec.Sudo(leader))
+	machineID, err := p.getNodeMachineID(node, leader)
 	if err != nil {
 		return err
 	}
@@ -106,3 +138,7 @@ func (p *ValidateHostsExtended) validateAndDeleteNode(node string, configWorkerM
 
 	return nil
 }
+
+func (p *ValidateHostsExtended) getNodeMachineID(node string, leader *k0sctl_cluster.Host) (string, error) {
+	return leader.ExecOutput(leader.Configurer.KubectlCmdf(leader, leader.K0sDataDir(), fmt.Sprintf("describe node %s | grep -i 'Machine ID:' | awk '{print $3}'", node)), exec.Sudo(leader))
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// k0sctl_backup_default_timeout the default timeout for taking the backup.
+const k0sctl_backup_default_timeout = 30 * time.Minute
+
+func k0sctl_backup_schema() schema.Schema {
+	return schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Backup of a k0s cluster, taken with k0sctl and downloaded to a local directory. The archive is kept when the resource is destroyed.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Backup identifier, the path of the backup archive",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+
+			"destination_dir": schema.StringAttribute{
+				MarkdownDescription: "Local directory to download the backup archive to",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"hosts": k0sctlClusterHostsAttribute(),
+			"triggers": schema.MapAttribute{
+				MarkdownDescription: "Arbitrary values which cause a new backup to be taken when they change (e.g. the k0s version)",
+				Optional:            true,
+				ElementType:         types.StringType,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+
+			"file_path": schema.StringAttribute{
+				MarkdownDescription: "Path of the downloaded backup archive",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"size": schema.Int64Attribute{
+				MarkdownDescription: "Size of the backup archive in bytes",
+				Computed:            true,
+			},
+			"sha256": schema.StringAttribute{
+				MarkdownDescription: "SHA256 checksum of the backup archive",
+				Computed:            true,
+			},
+			"timestamp": schema.StringAttribute{
+				MarkdownDescription: "Time when the backup was taken (RFC3339)",
+				Computed:            true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": k0sctlBackupTimeoutsBlock(),
+		},
+	}
+}
+
+// k0sctlBackupTimeoutsBlock the timeouts block for taking the backup.
+func k0sctlBackupTimeoutsBlock() schema.Block {
+	b := timeouts.Block(context.Background(), timeouts.Opts{
+		Create:            true,
+		CreateDescription: "Timeout for the k0sctl backup (default 30m)",
+	}).(schema.SingleNestedBlock)
+
+	b.MarkdownDescription = "Timeouts for the k0sctl backup, as duration strings (e.g. \"30m\"). When the timeout expires the backup is cancelled, and the hosts are unlocked and disconnected."
+
+	return b
+}
+
+type k0sctlBackupSchemaModel struct {
+	Id types.String `tfsdk:"id"`
+
+	DestinationDir types.String `tfsdk:"destination_dir"`
+	Hosts          types.List   `tfsdk:"hosts"`
+	Triggers       types.Map    `tfsdk:"triggers"`
+
+	FilePath  types.String `tfsdk:"file_path"`
+	Size      types.Int64  `tfsdk:"size"`
+	Sha256    types.String `tfsdk:"sha256"`
+	Timestamp types.String `tfsdk:"timestamp"`
+
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// k0sctlDryRunPrivateKey the private state key which holds the state from before a dry run apply.
+//
+// A dry run apply changes nothing on the hosts, but terraform stores the planned configuration in the state. The prior
+// state is restored on the next refresh, so that the changes are planned again.
+const k0sctlDryRunPrivateKey = "dry_run_prior"
+
+// k0sctlPrivateStateSetter the private state of a resource response.
+type k0sctlPrivateStateSetter interface {
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// k0sctlSetDryRunPrior record the state from before a dry run apply in the private state. A null state records that the
+// resource was created by the dry run.
+func k0sctlSetDryRunPrior(ctx context.Context, p k0sctlPrivateStateSetter, prior tftypes.Value) diag.Diagnostics {
+	b, err := k0sctlEncodeDryRunPrior(prior)
+	if err != nil {
+		return diag.Diagnostics{diag.NewErrorDiagnostic("could not record the state from before the dry run", err.Error())}
+	}
+
+	return p.SetKey(ctx, k0sctlDryRunPrivateKey, b)
+}
+
+// k0sctlEncodeDryRunPrior encode a state value as a private state value, which must be json.
+func k0sctlEncodeDryRunPrior(prior tftypes.Value) ([]byte, error) {
+	dv, err := tfprotov6.NewDynamicValue(prior.Type(), prior)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(dv.MsgPack)
+}
+
+// k0sctlDecodeDryRunPrior decode a state value of the type t from a private state value.
+func k0sctlDecodeDryRunPrior(b []byte, t tftypes.Type) (tftypes.Value, error) {
+	var mp []byte
+
+	if err := json.Unmarshal(b, &mp); err != nil {
+		return tftypes.Value{}, err
+	}
+
+	v, err := (&tfprotov6.DynamicValue{MsgPack: mp}).Unmarshal(t)
+	if err != nil {
+		return tftypes.Value{}, fmt.Errorf("the state does not match the schema: %w", err)
+	}
+
+	return v, nil
+}
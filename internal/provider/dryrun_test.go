@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestK0sctlDryRunPrior(t *testing.T) {
+	typ := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"dry_run": tftypes.Bool,
+		"hosts":   tftypes.List{ElementType: tftypes.String},
+	}}
+
+	for _, prior := range []tftypes.Value{
+		tftypes.NewValue(typ, map[string]tftypes.Value{
+			"dry_run": tftypes.NewValue(tftypes.Bool, false),
+			"hosts": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{
+				tftypes.NewValue(tftypes.String, "controller1.example.org"),
+			}),
+		}),
+		// the resource was created by the dry run
+		tftypes.NewValue(typ, nil),
+	} {
+		b, err := k0sctlEncodeDryRunPrior(prior)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		restored, err := k0sctlDecodeDryRunPrior(b, typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !restored.Equal(prior) {
+			t.Errorf("the prior state changed in the round trip, got %s, expected %s", restored, prior)
+		}
+	}
+
+	// the schema changed since the dry run
+	b, err := k0sctlEncodeDryRunPrior(tftypes.NewValue(tftypes.Object{AttributeTypes: map[string]tftypes.Type{"dry_run": tftypes.String}}, map[string]tftypes.Value{
+		"dry_run": tftypes.NewValue(tftypes.String, "false"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k0sctlDecodeDryRunPrior(b, typ); err == nil {
+		t.Error("a state of another schema was decoded")
+	}
+	if _, err := k0sctlDecodeDryRunPrior([]byte("{}"), typ); err == nil {
+		t.Error("an invalid private state value was decoded")
+	}
+}
@@ -0,0 +1,263 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	k0s_rig "github.com/k0sproject/rig"
+	"github.com/k0sproject/rig/pkg/ssh/hostkey"
+
+	k0sctl_v1beta1_cluster "github.com/k0sproject/k0sctl/pkg/apis/k0sctl.k0sproject.io/v1beta1/cluster"
+)
+
+const k0sctlHostKeyCheckTimeout = 30 * time.Second
+
+// k0sctlHostKeyAlgorithms the host key types in the order of preference of the ssh client, so that the key which is
+// recorded on first use is the one which is negotiated on the next connection.
+var k0sctlHostKeyAlgorithms = []string{
+	ssh.KeyAlgoECDSA256,
+	ssh.KeyAlgoECDSA384,
+	ssh.KeyAlgoECDSA521,
+	ssh.KeyAlgoRSASHA256,
+	ssh.KeyAlgoRSASHA512,
+	ssh.KeyAlgoRSA,
+	ssh.KeyAlgoDSA, //nolint:staticcheck
+	ssh.KeyAlgoED25519,
+}
+
+// errK0sctlHostKeyChecked aborts the connection to the last hop once its host key was checked, so that the host
+// itself is only logged in to by k0sctl.
+var errK0sctlHostKeyChecked = errors.New("host key checked")
+
+// k0sctlHostKeyString the "type base64" form of a public key, which is what rig compares a pinned host key against.
+func k0sctlHostKeyString(pk ssh.PublicKey) string {
+	return pk.Type() + " " + base64.StdEncoding.EncodeToString(pk.Marshal())
+}
+
+// k0sctlHostKeyNormalize interpret a host key given in authorized_keys ("type base64 [comment]") or known_hosts
+// ("host type base64") format, e.g. the output of ssh-keyscan.
+func k0sctlHostKeyNormalize(hk string) (string, error) {
+	if pk, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hk)); err == nil {
+		return k0sctlHostKeyString(pk), nil
+	}
+
+	if _, _, pk, _, _, err := ssh.ParseKnownHosts([]byte(hk)); err == nil {
+		return k0sctlHostKeyString(pk), nil
+	}
+
+	return "", fmt.Errorf("the host key is neither in authorized_keys (\"ssh-ed25519 AAAA...\") nor in known_hosts (\"host ssh-ed25519 AAAA...\") format")
+}
+
+// k0sctlKnownHostsPath the known_hosts file which rig records unknown host keys in, empty if SSH_KNOWN_HOSTS is
+// empty, which disables host key checking.
+//
+// rig also honours UserKnownHostsFile from the ssh config, which is not considered here.
+func k0sctlKnownHostsPath() (string, error) {
+	if p, ok := hostkey.KnownHostsPathFromEnv(); ok {
+		return p, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, strings.TrimPrefix(hostkey.DefaultKnownHostsPath, "~/")), nil
+}
+
+// k0sctlSSHHop an ssh connection on the way to a host, with the host_key attribute it is checked against.
+type k0sctlSSHHop struct {
+	ssh     *k0s_rig.SSH
+	hostKey *types.String
+	tofu    bool
+	path    path.Path
+}
+
+// capture whether the host key is recorded from the connection, on the first one of a trust_on_first_use host.
+func (hop k0sctlSSHHop) capture() bool {
+	return hop.tofu && hop.ssh.HostKey == "" && hop.hostKey.IsUnknown()
+}
+
+// hostKeyCallback the host key check of the hop, which records the key presented to a trust_on_first_use hop.
+func (hop k0sctlSSHHop) hostKeyCallback(captured *ssh.PublicKey) (ssh.HostKeyCallback, error) {
+	if hop.ssh.HostKey != "" {
+		return hostkey.StaticKeyCallback(hop.ssh.HostKey), nil
+	}
+
+	if hop.capture() {
+		return func(_ string, _ net.Addr, pk ssh.PublicKey) error {
+			*captured = pk
+			return nil
+		}, nil
+	}
+
+	khp, err := k0sctlKnownHostsPath()
+	if err != nil {
+		return nil, err
+	}
+	if khp == "" {
+		return hostkey.InsecureIgnoreHostKeyCallback, nil
+	}
+
+	return hostkey.KnownHostsFileCallback(khp, false, false)
+}
+
+// CheckHostKeys connect to the ssh hosts and bastions which have a host_key, or which are trust_on_first_use, before
+// k0sctl does, so that a mismatch is reported on the host_key attribute, and the host key presented on the first
+// connection is recorded in the model and pinned for k0sctl.
+func (ksm *k0sctlSchemaModel) CheckHostKeys(ctx context.Context, hosts k0sctl_v1beta1_cluster.Hosts) diag.Diagnostics {
+	d := diag.Diagnostics{}
+
+	for i, h := range hosts {
+		if i >= len(ksm.Spec.Hosts) {
+			break
+		}
+
+		sh := &ksm.Spec.Hosts[i]
+		shp := path.Root("spec").AtName("host").AtListIndex(i)
+
+		// only ssh hosts present host keys, winrm hosts can be connected to through ssh bastions
+		var hops []k0sctlSSHHop
+		var bastion *k0s_rig.SSH
+		var bastions []k0sctlSchemaModelSpecHostSSHBastion
+		var bp path.Path
+
+		if len(sh.SSH) > 0 && h.SSH != nil {
+			shssh := &sh.SSH[0]
+			bp = shp.AtName("ssh").AtListIndex(0)
+			hops = append(hops, k0sctlSSHHop{ssh: h.SSH, hostKey: &shssh.HostKey, tofu: shssh.TrustOnFirstUse.ValueBool(), path: bp})
+			bastion = h.SSH.Bastion
+			bastions = shssh.Bastion
+		} else if len(sh.WinRM) > 0 && h.WinRM != nil {
+			bp = shp.AtName("winrm").AtListIndex(0)
+			bastion = h.WinRM.Bastion
+			bastions = sh.WinRM[0].Bastion
+		} else {
+			continue
+		}
+
+		// rig chains the bastions from the last one, which is connected to through the previous ones
+		for j := len(bastions) - 1; j >= 0 && bastion != nil; j-- {
+			shb := &bastions[j]
+			hops = append([]k0sctlSSHHop{{ssh: bastion, hostKey: &shb.HostKey, tofu: shb.TrustOnFirstUse.ValueBool(), path: bp.AtName("bastion").AtListIndex(j)}}, hops...)
+			bastion = bastion.Bastion
+		}
+
+		check := false
+		for _, hop := range hops {
+			check = check || hop.ssh.HostKey != "" || hop.capture()
+		}
+		if !check {
+			continue
+		}
+
+		tflog.Info(ctx, "checking host keys", map[string]interface{}{"host": h.Address()})
+		d.Append(k0sctlCheckHostKeys(ctx, hops)...)
+	}
+
+	return d
+}
+
+// k0sctlCheckHostKeys connect through the hops, up to the key exchange with the last one.
+func k0sctlCheckHostKeys(ctx context.Context, hops []k0sctlSSHHop) diag.Diagnostics {
+	d := diag.Diagnostics{}
+
+	// each client tunnels the connection to the next hop
+	var clients []*ssh.Client
+	defer func() {
+		for j := len(clients) - 1; j >= 0; j-- {
+			clients[j].Close()
+		}
+	}()
+
+	for i, hop := range hops {
+		hp := net.JoinHostPort(hop.ssh.Address, strconv.Itoa(hop.ssh.Port))
+		last := i == len(hops)-1
+
+		var captured ssh.PublicKey
+		hkc, err := hop.hostKeyCallback(&captured)
+		if err != nil {
+			d.AddAttributeError(hop.path.AtName("host_key"), "Could not check the host key", fmt.Sprintf("The host key of %s could not be checked: %s", hp, err))
+			return d
+		}
+
+		cc := &ssh.ClientConfig{
+			User: hop.ssh.User,
+			Auth: hop.ssh.AuthMethods,
+			HostKeyCallback: func(hostname string, remote net.Addr, pk ssh.PublicKey) error {
+				if err := hkc(hostname, remote, pk); err != nil || !last {
+					return err
+				}
+				return errK0sctlHostKeyChecked
+			},
+		}
+		if hop.capture() {
+			cc.HostKeyAlgorithms = k0sctlHostKeyAlgorithms
+		}
+
+		// the hops after the first one are tunneled through the previous ones, the deadline covers all of them
+		var conn net.Conn
+		if len(clients) == 0 {
+			conn, err = (&net.Dialer{Timeout: k0sctlHostKeyCheckTimeout}).DialContext(ctx, "tcp", hp)
+			if err == nil {
+				_ = conn.SetDeadline(time.Now().Add(k0sctlHostKeyCheckTimeout))
+			}
+		} else {
+			conn, err = clients[len(clients)-1].Dial("tcp", hp)
+		}
+		if err != nil {
+			d.AddError("SSH connection failed", fmt.Sprintf("Could not connect to %s to check its host key: %s", hp, err))
+			return d
+		}
+
+		c, chans, reqs, err := ssh.NewClientConn(conn, hp, cc)
+		if errors.Is(err, hostkey.ErrHostKeyMismatch) {
+			d.AddAttributeError(hop.path.AtName("host_key"), "SSH host key mismatch", fmt.Sprintf("The host %s presented a host key which does not match its host_key, or its known_hosts entry. The connection was refused, as the host could be impersonated.\n\n%s", hp, err))
+			return d
+		} else if err != nil && !errors.Is(err, errK0sctlHostKeyChecked) {
+			d.AddError("SSH connection failed", fmt.Sprintf("Could not connect to %s to check its host key: %s", hp, err))
+			return d
+		}
+
+		if captured != nil {
+			hk := k0sctlHostKeyString(captured)
+			hop.ssh.HostKey = hk
+			*hop.hostKey = types.StringValue(hk)
+		}
+
+		if last {
+			break
+		}
+
+		clients = append(clients, ssh.NewClient(c, chans, reqs))
+	}
+
+	return d
+}
+
+// k0sctlHostKeyMismatchDiagnostics diagnostics for an error caused by a host key mismatch, empty if the error is not
+// caused by one.
+func k0sctlHostKeyMismatchDiagnostics(err error) diag.Diagnostics {
+	d := diag.Diagnostics{}
+
+	if errors.Is(err, hostkey.ErrHostKeyMismatch) {
+		d.AddError("SSH host key mismatch", fmt.Sprintf("A host presented a host key which does not match its host_key, or its known_hosts entry. The connection was refused, as the host could be impersonated.\n\n%s", err))
+	}
+
+	return d
+}
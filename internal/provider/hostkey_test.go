@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	k0s_rig "github.com/k0sproject/rig"
+	"github.com/k0sproject/rig/pkg/ssh/hostkey"
+
+	k0sctl_v1beta1_cluster "github.com/k0sproject/k0sctl/pkg/apis/k0sctl.k0sproject.io/v1beta1/cluster"
+)
+
+func TestK0sctlHostKey(t *testing.T) {
+	edpk, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, err := ssh.NewPublicKey(edpk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := k0sctlHostKeyString(pk)
+
+	// the pinned key must be what rig compares against
+	if err := hostkey.StaticKeyCallback(want)("", nil, pk); err != nil {
+		t.Errorf("rig rejected the host key string: %s", err)
+	}
+
+	for _, hk := range []string{
+		want,
+		string(ssh.MarshalAuthorizedKey(pk)),
+		want + " root@dc1-controller1",
+		knownhosts.Line([]string{"dc1-controller1"}, pk),
+	} {
+		if got, err := k0sctlHostKeyNormalize(hk); err != nil {
+			t.Errorf("could not normalize host key %q: %s", hk, err)
+		} else if got != want {
+			t.Errorf("normalized host key %q, expected %q", got, want)
+		}
+	}
+
+	if _, err := k0sctlHostKeyNormalize("dc1-controller1 not-a-key"); err == nil {
+		t.Error("normalized an invalid host key")
+	}
+
+	if d := k0sctlHostKeyMismatchDiagnostics(errors.New("failed on 1 hosts:\n - [ssh] dc1-worker1:22: ssh dial: timeout")); d.HasError() {
+		t.Errorf("an unrelated error was reported as a host key mismatch: %v", d)
+	}
+	if d := k0sctlHostKeyMismatchDiagnostics(fmt.Errorf("client connect: %w", hostkey.ErrHostKeyMismatch)); !d.HasError() {
+		t.Error("a host key mismatch was not reported")
+	}
+}
+
+func TestK0sctlCheckHostKeys(t *testing.T) {
+	pk, signer := testK0sctlSSHKey(t)
+	otherpk, _ := testK0sctlSSHKey(t)
+
+	sc := &ssh.ServerConfig{NoClientAuth: true}
+	sc.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _, _, _ = ssh.NewServerConn(conn, sc)
+			}()
+		}
+	}()
+
+	port := l.Addr().(*net.TCPAddr).Port
+	hkp := path.Root("spec").AtName("host").AtListIndex(0).AtName("ssh").AtListIndex(0).AtName("host_key")
+
+	check := func(hostKey types.String, tofu bool) (k0sctlSchemaModel, *k0s_rig.SSH, diag.Diagnostics) {
+		ksm := k0sctlSchemaModel{Spec: k0sctlSchemaModelSpec{Hosts: []k0sctlSchemaModelSpecHost{
+			{SSH: []k0sctlSchemaModelSpecHostSSH{{HostKey: hostKey, TrustOnFirstUse: types.BoolValue(tofu)}}},
+		}}}
+		hk, _ := k0sctlSSHHostKey(hostKey, hkp)
+		rs := &k0s_rig.SSH{Address: "127.0.0.1", Port: port, User: "root", HostKey: hk}
+		d := ksm.CheckHostKeys(context.Background(), k0sctl_v1beta1_cluster.Hosts{{Connection: k0s_rig.Connection{SSH: rs}}})
+		return ksm, rs, d
+	}
+
+	// the key presented on the first connection is recorded and pinned
+	ksm, rs, d := check(types.StringUnknown(), true)
+	if d.HasError() {
+		t.Fatalf("checking the host key failed: %v", d)
+	}
+	if got := ksm.Spec.Hosts[0].SSH[0].HostKey.ValueString(); got != k0sctlHostKeyString(pk) {
+		t.Errorf("recorded host key %q, expected %q", got, k0sctlHostKeyString(pk))
+	}
+	if rs.HostKey != k0sctlHostKeyString(pk) {
+		t.Errorf("pinned host key %q, expected %q", rs.HostKey, k0sctlHostKeyString(pk))
+	}
+
+	if _, _, d := check(types.StringValue(k0sctlHostKeyString(pk)), true); d.HasError() {
+		t.Errorf("the recorded host key was not accepted: %v", d)
+	}
+
+	_, _, d = check(types.StringValue(k0sctlHostKeyString(otherpk)), false)
+	if len(d) != 1 {
+		t.Fatalf("expected one diagnostic for a host key mismatch, got %v", d)
+	} else if dp, ok := d[0].(diag.DiagnosticWithPath); !ok || !dp.Path().Equal(hkp) || d[0].Summary() != "SSH host key mismatch" {
+		t.Errorf("host key mismatch was not reported on %s: %v", hkp, d[0])
+	}
+}
+
+func testK0sctlSSHKey(t *testing.T) (ssh.PublicKey, ssh.Signer) {
+	_, edsk, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(edsk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer.PublicKey(), signer
+}
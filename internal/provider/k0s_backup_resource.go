@@ -0,0 +1,204 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	k0sctl_phase "github.com/k0sproject/k0sctl/phase"
+
+	provider_action "github.com/mirantis/terraform-provider-k0sctl/internal/k0sctl/action"
+)
+
+var _ resource.Resource = &K0sctlBackupResource{}
+
+type K0sctlBackupResource struct {
+	testingMode bool
+}
+
+func NewK0sctlBackupResource() resource.Resource {
+	return &K0sctlBackupResource{}
+}
+
+func (r *K0sctlBackupResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_backup"
+}
+
+func (r *K0sctlBackupResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = k0sctl_backup_schema()
+}
+
+func (r *K0sctlBackupResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	kpm, ok := req.ProviderData.(*K0sctlProviderModel)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *K0sctlProviderModel, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.testingMode = kpm.testingMode
+}
+
+func (r *K0sctlBackupResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var kbsm k0sctlBackupSchemaModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &kbsm)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	kcc, ds := k0sctlClusterHosts(ctx, kbsm.Hosts, "k0s-backup")
+	resp.Diagnostics.Append(ds...)
+
+	var pm *k0sctl_phase.Manager
+
+	if tpm, err := k0sctl_phase.NewManager(&kcc); err != nil {
+		d := diag.NewErrorDiagnostic("k0sctl phase manager creation failed", err.Error())
+		resp.Diagnostics.Append(d)
+	} else {
+		pm = tpm
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ts := time.Now().UTC()
+	fp := filepath.Join(kbsm.DestinationDir.ValueString(), fmt.Sprintf("k0s_backup_%s.tar.gz", ts.Format("2006-01-02T15_04_05")))
+
+	kbsm.Id = types.StringValue(fp)
+	kbsm.FilePath = types.StringValue(fp)
+	kbsm.Timestamp = types.StringValue(ts.Format(time.RFC3339))
+	kbsm.Size = types.Int64Null()
+	kbsm.Sha256 = types.StringNull()
+
+	timeout, diags := kbsm.Timeouts.Create(ctx, k0sctl_backup_default_timeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	actx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if r.testingMode {
+		resp.Diagnostics.AddWarning("testing mode warning", "k0sctl backup resource handler is in testing mode, no backup will be taken.")
+		resp.Diagnostics.Append(resp.State.Set(ctx, kbsm)...)
+		return
+	}
+
+	if err := os.MkdirAll(kbsm.DestinationDir.ValueString(), 0o750); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("destination_dir"), "Could not create the backup destination directory", err.Error())
+		return
+	}
+
+	// download to a temporary file, so that a failed backup does not leave a partial archive behind
+	f, err := os.CreateTemp(kbsm.DestinationDir.ValueString(), ".k0s_backup_*.tar.gz")
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("destination_dir"), "Could not create the backup archive", err.Error())
+		return
+	}
+	defer os.Remove(f.Name())
+
+	h := sha256.New()
+
+	ba := provider_action.Backup{
+		Manager: pm,
+		Out:     io.MultiWriter(f, h),
+	}
+
+	err = ba.Run(actx)
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		resp.Diagnostics.Append(diag.NewErrorDiagnostic("error running k0sctl backup", err.Error()))
+		return
+	}
+
+	if err := os.Rename(f.Name(), fp); err != nil {
+		resp.Diagnostics.AddError("Could not move the backup archive into place", err.Error())
+		return
+	}
+
+	fi, err := os.Stat(fp)
+	if err != nil {
+		resp.Diagnostics.AddError("Could not read the backup archive", err.Error())
+		return
+	}
+
+	kbsm.Size = types.Int64Value(fi.Size())
+	kbsm.Sha256 = types.StringValue(hex.EncodeToString(h.Sum(nil)))
+
+	tflog.Info(ctx, "k0sctl backup taken", map[string]interface{}{"file": fp, "size": fi.Size()})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, kbsm)...)
+}
+
+// Read check that the backup archive is still in place, so that a missing or altered archive is taken again.
+func (r *K0sctlBackupResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var kbsm k0sctlBackupSchemaModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &kbsm)...)
+
+	if resp.Diagnostics.HasError() || r.testingMode {
+		return
+	}
+
+	f, err := os.Open(kbsm.FilePath.ValueString())
+	if err != nil {
+		tflog.Warn(ctx, "k0sctl backup archive is missing", map[string]interface{}{"file": kbsm.FilePath.ValueString(), "error": err.Error()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		resp.Diagnostics.AddError("Could not read the backup archive", err.Error())
+		return
+	}
+
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != kbsm.Sha256.ValueString() {
+		tflog.Warn(ctx, "k0sctl backup archive has changed", map[string]interface{}{"file": kbsm.FilePath.ValueString()})
+		resp.State.RemoveResource(ctx)
+	}
+}
+
+func (r *K0sctlBackupResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// all of the arguments require replacement, so there is never anything to update in place
+	var kbsm k0sctlBackupSchemaModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &kbsm)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, kbsm)...)
+}
+
+// Delete remove the backup from state, the backup archive itself is kept.
+func (r *K0sctlBackupResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.State.RemoveResource(ctx)
+}
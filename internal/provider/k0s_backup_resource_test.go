@@ -0,0 +1,39 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccK0sctlBackupResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccK0sctlBackupResourceConfig_minimal(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("k0sctl_backup.test", "destination_dir", "./backups"),
+					resource.TestCheckResourceAttrSet("k0sctl_backup.test", "file_path"),
+					resource.TestCheckResourceAttrSet("k0sctl_backup.test", "timestamp"),
+					resource.TestCheckResourceAttr("k0sctl_backup.test", "hosts.0.ssh.0.address", "controller1.example.org"),
+				),
+			},
+		},
+	})
+}
+
+func testAccK0sctlBackupResourceConfig_minimal() string {
+	return testAccK0sctlConfigResourceConfig_minimal() + `
+resource "k0sctl_backup" "test" {
+    destination_dir = "./backups"
+    hosts           = k0sctl_config.test.spec.host
+
+    timeouts {
+        create = "10m"
+    }
+}
+`
+}
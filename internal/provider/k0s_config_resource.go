@@ -5,22 +5,34 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
 	k0sctl_phase "github.com/k0sproject/k0sctl/phase"
-
-	k0sctl_action "github.com/k0sproject/k0sctl/action"
+	k0sversion "github.com/k0sproject/version"
 
 	provider_action "github.com/mirantis/terraform-provider-k0sctl/internal/k0sctl/action"
 
 	k0sctl_v1beta1 "github.com/k0sproject/k0sctl/pkg/apis/k0sctl.k0sproject.io/v1beta1"
+	k0sctl_v1beta1_cluster "github.com/k0sproject/k0sctl/pkg/apis/k0sctl.k0sproject.io/v1beta1/cluster"
 )
 
 var _ resource.Resource = &K0sctlConfigResource{}
+var _ resource.ResourceWithImportState = &K0sctlConfigResource{}
+var _ resource.ResourceWithValidateConfig = &K0sctlConfigResource{}
+var _ resource.ResourceWithUpgradeState = &K0sctlConfigResource{}
+var _ resource.ResourceWithModifyPlan = &K0sctlConfigResource{}
+
+// k0sctlVersionDriftPrivateKey the private state key which records that the hosts run another version than the one
+// resolved from the version channel.
+const k0sctlVersionDriftPrivateKey = "k0s_version_drift"
 
 type K0sctlConfigResource struct {
 	testingMode bool
@@ -58,6 +70,291 @@ func (r *K0sctlConfigResource) Configure(ctx context.Context, req resource.Confi
 	r.testingMode = kpm.testingMode
 }
 
+// UpgradeState upgrade the state of prior schema versions.
+func (r *K0sctlConfigResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			StateUpgrader: k0sctlUpgradeStateV0,
+		},
+	}
+}
+
+// ValidateConfig run the k0sctl cluster conversion and validation at plan time.
+func (r *K0sctlConfigResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var kcsm k0sctlSchemaModel
+
+	resp.Diagnostics.Append(k0sctlSchemaModelFromKnown(ctx, req.Config.Raw, &kcsm)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tkcc, ds := kcsm.Cluster(ctx)
+	resp.Diagnostics.Append(ds...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// some values may only be known after apply (e.g. host addresses), so only the known values are validated
+	if err := tkcc.Validate(); err != nil {
+		resp.Diagnostics.Append(k0sctlKnownDiagnostics(req.Config.Raw, k0sctlValidationDiagnostics(err))...)
+	}
+}
+
+// ModifyPlan resolve the k0s version and check the upgrade path, validate the k0s config, and plan the dry run report.
+func (r *K0sctlConfigResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		// the resource is destroyed
+		return
+	}
+
+	if r.modifyPlanUnchanged(ctx, req, resp) {
+		return
+	}
+
+	r.modifyPlanVersion(ctx, req, resp)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.modifyPlanK0sConfig(ctx, req, resp)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.modifyPlanDryRun(ctx, req, resp)
+}
+
+// modifyPlanUnchanged plan the prior state if nothing changed but the formatting of the k0s config. The framework
+// still considers it a change, and plans the computed attributes as unknown.
+//
+// A configuration with unknown values (e.g. an address from a replaced resource) is a change, which is only
+// known after apply.
+func (r *K0sctlConfigResource) modifyPlanUnchanged(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) bool {
+	if req.State.Raw.IsNull() || !req.Config.Raw.IsFullyKnown() {
+		return false
+	}
+
+	// with a known configuration, the unknown values are the computed ones, which keep their prior values if
+	// nothing changed
+	pv, err := tftypes.Transform(resp.Plan.Raw, func(p *tftypes.AttributePath, v tftypes.Value) (tftypes.Value, error) {
+		if v.IsKnown() {
+			return v, nil
+		}
+
+		sv, _, err := tftypes.WalkAttributePath(req.State.Raw, p)
+		if err != nil {
+			return v, err
+		}
+		if svv, ok := sv.(tftypes.Value); ok {
+			return svv, nil
+		}
+		return v, nil
+	})
+	if err != nil || !pv.Equal(req.State.Raw) {
+		return false
+	}
+
+	tflog.Debug(ctx, "only the formatting of the k0s config changed, planning the prior state")
+	resp.Plan.Raw = req.State.Raw
+
+	return true
+}
+
+// priorResolvedVersion the resolved version of the state, which is kept if the version channel is unchanged. It is
+// null if the version has to be looked up, on create, when the version channel or endpoint changed, or when the hosts
+// run another version.
+func (r *K0sctlConfigResource) priorResolvedVersion(ctx context.Context, req resource.ModifyPlanRequest, channel, endpoint types.String) (types.String, diag.Diagnostics) {
+	d := diag.Diagnostics{}
+	k0sp := path.Root("spec").AtName("k0s")
+
+	if req.State.Raw.IsNull() {
+		return types.StringNull(), d
+	}
+
+	var resolved, priorChannel, priorEndpoint types.String
+
+	d.Append(req.State.GetAttribute(ctx, k0sp.AtName("resolved_version"), &resolved)...)
+	d.Append(req.State.GetAttribute(ctx, k0sp.AtName("version_channel"), &priorChannel)...)
+	d.Append(req.State.GetAttribute(ctx, k0sp.AtName("version_endpoint"), &priorEndpoint)...)
+
+	b, ds := req.Private.GetKey(ctx, k0sctlVersionDriftPrivateKey)
+	d.Append(ds...)
+
+	if d.HasError() || b != nil || !channel.Equal(priorChannel) || !endpoint.Equal(priorEndpoint) {
+		return types.StringNull(), d
+	}
+
+	return resolved, d
+}
+
+// modifyPlanVersion resolve the k0s version of the version channel, if no version is given, and check the upgrade
+// path from the prior version.
+func (r *K0sctlConfigResource) modifyPlanVersion(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	k0sp := path.Root("spec").AtName("k0s")
+
+	var version, channel, endpoint types.String
+
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, k0sp.AtName("version"), &version)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, k0sp.AtName("version_channel"), &channel)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, k0sp.AtName("version_endpoint"), &endpoint)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resolved := types.StringUnknown()
+
+	if version.IsUnknown() {
+		// the resolved version is only known after apply as well
+	} else if !version.IsNull() {
+		resolved = version
+	} else if prior, ds := r.priorResolvedVersion(ctx, req, channel, endpoint); ds.HasError() {
+		resp.Diagnostics.Append(ds...)
+		return
+	} else if !prior.IsNull() {
+		// the version channel is only looked up again when it changes, or when another version is running
+		resolved = prior
+	} else if channel.IsUnknown() || endpoint.IsUnknown() || r.testingMode {
+		// k0sctl resolves the version when applying
+	} else if v, err := k0sctlK0sChannelVersion(ctx, endpoint.ValueString(), channel.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(k0sp.AtName("version_endpoint"), "Could not look up the k0s version", fmt.Sprintf("The latest k0s version of the %s channel could not be looked up, set a version or a reachable version_endpoint: %s", channel.ValueString(), err))
+		return
+	} else {
+		resolved = types.StringValue(v.String())
+	}
+
+	if !resolved.IsUnknown() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, k0sp.AtName("resolved_version"), resolved)...)
+	}
+
+	if req.State.Raw.IsNull() || resolved.IsUnknown() {
+		// nothing is installed yet, or the version to install is not known
+		return
+	}
+
+	var pkcsm, kcsm k0sctlSchemaModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &pkcsm)...)
+	resp.Diagnostics.Append(k0sctlSchemaModelFromKnown(ctx, resp.Plan.Raw, &kcsm)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// states from before the resolved version was recorded only have the version
+	pv := pkcsm.Spec.K0s.ResolvedVersion
+	if pv.IsNull() {
+		pv = pkcsm.Spec.K0s.Version
+	}
+
+	prior, err := k0sversion.NewVersion(pv.ValueString())
+	if err != nil {
+		return
+	}
+	planned, err := k0sversion.NewVersion(resolved.ValueString())
+	if err != nil {
+		// the version is reported as invalid by the config validation
+		return
+	}
+
+	var controllers, workers []string
+
+	if kcc, ds := kcsm.Cluster(ctx); !ds.HasError() {
+		for _, h := range kcc.Spec.Hosts {
+			if h.IsController() {
+				controllers = append(controllers, fmt.Sprintf("%s (%s)", h.Address(), h.Role))
+			} else {
+				workers = append(workers, h.Address())
+			}
+		}
+	}
+
+	resp.Diagnostics.Append(k0sctlK0sUpgradeDiagnostics(prior, planned, kcsm.DisableDowngradeCheck.ValueBool(), controllers, workers)...)
+}
+
+// modifyPlanK0sConfig validate the k0s config against the ClusterConfig schema of the resolved version, so that
+// mistakes are found when planning instead of by k0s on the controllers. The schema is not downloaded in testing
+// mode, like the version channel is not looked up.
+func (r *K0sctlConfigResource) modifyPlanK0sConfig(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	k0sp := path.Root("spec").AtName("k0s")
+
+	var config types.Dynamic
+	var resolved, endpoint types.String
+
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, k0sp.AtName("config"), &config)...)
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, k0sp.AtName("resolved_version"), &resolved)...)
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, k0sp.AtName("config_schema_endpoint"), &endpoint)...)
+
+	if resp.Diagnostics.HasError() || r.testingMode {
+		return
+	}
+
+	if config.IsNull() || config.IsUnknown() || resolved.IsUnknown() || resolved.IsNull() || endpoint.IsUnknown() || endpoint.ValueString() == "" {
+		// there is nothing to validate, or the config is only validated by k0s when applying
+		return
+	}
+
+	v, err := k0sversion.NewVersion(resolved.ValueString())
+	if err != nil {
+		// the version is reported as invalid by the config validation
+		return
+	}
+
+	s, err := k0sctlK0sConfigSchemaDownload(ctx, endpoint.ValueString(), v)
+	if err != nil {
+		resp.Diagnostics.AddAttributeWarning(k0sp.AtName("config_schema_endpoint"), "Could not download the k0s config schema", fmt.Sprintf("The ClusterConfig schema of k0s %s could not be downloaded, the k0s config is only validated by k0s when applying: %s", v, err))
+		return
+	}
+
+	resp.Diagnostics.Append(k0sctlK0sConfigDiagnostics(config, s, v.String())...)
+}
+
+// modifyPlanDryRun plan the dry run report when dry_run is set. The dry run is only run when applying, so that
+// planning neither connects to the hosts nor needs them to be reachable.
+func (r *K0sctlConfigResource) modifyPlanDryRun(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	drrp := path.Root("dry_run_report")
+
+	var dryRun types.Bool
+
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("dry_run"), &dryRun)...)
+
+	if resp.Diagnostics.HasError() || dryRun.IsUnknown() {
+		return
+	}
+
+	if !dryRun.ValueBool() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, drrp, types.ListNull(k0sctlDryRunReportType))...)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, drrp, types.ListUnknown(k0sctlDryRunReportType))...)
+}
+
+// dryRunApply run the k0sctl apply in dry-run mode, which changes nothing on the hosts, and record its report.
+func (r *K0sctlConfigResource) dryRunApply(ctx context.Context, kcsm *k0sctlSchemaModel, aa provider_action.Apply) diag.Diagnostics {
+	d := diag.Diagnostics{}
+
+	if r.testingMode {
+		d.AddWarning("testing mode warning", "k0sctl config resource handler is in testing mode, no dry run will be run.")
+		kcsm.DryRunReport = types.ListNull(k0sctlDryRunReportType)
+	} else if err := aa.Run(ctx); err != nil {
+		d.Append(k0sctlHostKeyMismatchDiagnostics(err)...)
+		d.AddError("error running the k0sctl dry run", err.Error())
+		return d
+	} else {
+		d.Append(kcsm.AddDryRunReport(ctx, aa.Operations())...)
+	}
+
+	d.AddWarning("dry run", "The k0sctl apply was run in dry-run mode, no changes were made to the hosts. See dry_run_report for the operations that would be performed. The prior configuration is restored in the state on the next refresh, so that the changes are planned again.")
+	d.Append(kcsm.AddHostKeys(ctx)...)
+
+	return d
+}
+
 func (r *K0sctlConfigResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var kcsm k0sctlSchemaModel
 	var kcc k0sctl_v1beta1.Cluster
@@ -71,7 +368,7 @@ func (r *K0sctlConfigResource) Create(ctx context.Context, req resource.CreateRe
 	if tkcc, ds := kcsm.Cluster(ctx); ds.HasError() {
 		resp.Diagnostics.Append(ds...)
 	} else if err := tkcc.Validate(); err != nil {
-		resp.Diagnostics.Append(diag.NewErrorDiagnostic("k0sctl cluster validation failed", err.Error()))
+		resp.Diagnostics.Append(k0sctlValidationDiagnostics(err)...)
 	} else {
 		kcc = tkcc
 	}
@@ -84,6 +381,7 @@ func (r *K0sctlConfigResource) Create(ctx context.Context, req resource.CreateRe
 		resp.Diagnostics.Append(d)
 	} else {
 		pm = tpm
+		pm.DryRun = kcsm.DryRun.ValueBool()
 	}
 
 	if resp.Diagnostics.HasError() {
@@ -92,35 +390,64 @@ func (r *K0sctlConfigResource) Create(ctx context.Context, req resource.CreateRe
 
 	kc = bytes.NewBuffer([]byte{})
 
-	aa := k0sctl_action.Apply{
-		Force:         kcsm.Force.ValueBool(),
-		Manager:       pm,
-		KubeconfigOut: kc,
-		//KubeconfigAPIAddress:  kcsm.??
+	aa := provider_action.Apply{
+		Force:                 kcsm.Force.ValueBool(),
+		Manager:               pm,
+		KubeconfigOut:         kc,
+		KubeconfigAPIAddress:  kcsm.APIAddress(kcc),
 		NoWait:                kcsm.NoWait.ValueBool(),
 		NoDrain:               kcsm.NoDrain.ValueBool(),
 		DisableDowngradeCheck: kcsm.DisableDowngradeCheck.ValueBool(),
 		RestoreFrom:           kcsm.RestoreFrom.ValueString(),
 	}
 
+	timeout, diags := kcsm.Timeouts.Create(ctx, k0sctl_default_timeouts["create"])
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	actx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	kcsm.KubeYaml = types.StringNull()
+	kcsm.Kubeconfig = types.ObjectNull(k0sctlKubeconfigType.AttrTypes)
 	kcsm.KubeHost = types.StringNull()
 	kcsm.CaCert = types.StringNull()
 	kcsm.PrivateKey = types.StringNull()
 	kcsm.ClientCert = types.StringNull()
 	kcsm.Id = kcsm.Metadata.Name
 
+	if !pm.DryRun {
+		kcsm.DryRunReport = types.ListNull(k0sctlDryRunReportType)
+	}
+
 	if kcsm.SkipCreate.ValueBool() {
 		resp.Diagnostics.AddWarning("skipping create", "Skipping the k0sctl create because of configuration flag.")
+		resp.Diagnostics.Append(kcsm.AddHostKeys(ctx)...)
+		kcsm.AddResolvedVersion(kcc)
 		resp.Diagnostics.Append(resp.State.Set(ctx, kcsm)...)
+	} else if pm.DryRun {
+		resp.Diagnostics.Append(r.dryRunApply(actx, &kcsm, aa)...)
+		kcsm.AddResolvedVersion(kcc)
+
+		// nothing was installed, so the resource is removed again on the next refresh
+		resp.Diagnostics.Append(k0sctlSetDryRunPrior(ctx, resp.Private, tftypes.NewValue(req.Plan.Raw.Type(), nil))...)
 	} else if r.testingMode {
 		resp.Diagnostics.AddWarning("testing mode warning", "k0sctl config resource handler is in testing mode, no installation will be run.")
+		resp.Diagnostics.Append(kcsm.AddHostKeys(ctx)...)
+		kcsm.AddResolvedVersion(kcc)
 		resp.Diagnostics.Append(resp.State.Set(ctx, kcsm)...)
-	} else if err := aa.Run(); err != nil {
+	} else if resp.Diagnostics.Append(kcsm.CheckHostKeys(actx, kcc.Spec.Hosts)...); resp.Diagnostics.HasError() {
+		// nothing was applied, the host keys could not be checked
+	} else if err := aa.Run(actx); err != nil {
+		resp.Diagnostics.Append(k0sctlHostKeyMismatchDiagnostics(err)...)
 		resp.Diagnostics.Append(diag.NewErrorDiagnostic("error running k0sctl apply", err.Error()))
 	} else {
 		// populate the model kubernetes conf from the action
-		resp.Diagnostics.Append(kcsm.AddKubeconfig(kc)...)
+		resp.Diagnostics.Append(kcsm.AddKubeconfig(ctx, kc)...)
+		resp.Diagnostics.Append(kcsm.AddHostKeys(ctx)...)
+		kcsm.AddResolvedVersion(kcc)
 	}
 
 	if resp.Diagnostics.HasError() {
@@ -135,14 +462,108 @@ func (r *K0sctlConfigResource) Create(ctx context.Context, req resource.CreateRe
 }
 
 func (r *K0sctlConfigResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// k0sctl has no good way to discover existing installation, so we don't do anything
+	var kcsm k0sctlSchemaModel
+	var kcc k0sctl_v1beta1.Cluster
+
+	// a dry run apply changed nothing on the hosts, so the state from before it is restored
+	if b, ds := req.Private.GetKey(ctx, k0sctlDryRunPrivateKey); ds.HasError() {
+		resp.Diagnostics.Append(ds...)
+		return
+	} else if b != nil {
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, k0sctlDryRunPrivateKey, nil)...)
+
+		if prior, err := k0sctlDecodeDryRunPrior(b, req.State.Raw.Type()); err != nil {
+			resp.Diagnostics.AddWarning("Could not restore the state from before the dry run", fmt.Sprintf("The changes of the dry run apply will not be planned again: %s", err))
+		} else if prior.IsNull() {
+			// the resource was created by the dry run, so nothing is installed
+			resp.State.RemoveResource(ctx)
+			return
+		} else {
+			var report types.List
+
+			resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("dry_run_report"), &report)...)
+			resp.State.Raw = prior
+			resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("dry_run_report"), report)...)
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Get(ctx, &kcsm)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if kcsm.SkipCreate.ValueBool() || r.testingMode {
+		// nothing was installed, so there is nothing to discover
+		return
+	}
+
+	if tkcc, ds := kcsm.Cluster(ctx); ds.HasError() {
+		resp.Diagnostics.Append(ds...)
+	} else {
+		kcc = tkcc
+	}
+
+	var pm *k0sctl_phase.Manager
+
+	if tpm, err := k0sctl_phase.NewManager(&kcc); err != nil {
+		d := diag.NewErrorDiagnostic("k0sctl phase manager creation failed", err.Error())
+		resp.Diagnostics.Append(d)
+	} else {
+		pm = tpm
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ra := provider_action.Read{
+		Manager:              pm,
+		KubeconfigAPIAddress: kcsm.APIAddress(kcc),
+	}
+
+	if kcsm.KubeYaml.IsNull() {
+		// no kubeconfig has been collected yet (e.g. after an import), so fetch one
+		ra.KubeconfigOut = bytes.NewBuffer([]byte{})
+	}
+
+	if err := ra.Run(ctx); err != nil {
+		resp.Diagnostics.Append(k0sctlHostKeyMismatchDiagnostics(err)...)
+		resp.Diagnostics.Append(diag.NewErrorDiagnostic("error reading k0s state from the hosts", err.Error()))
+		return
+	}
+
+	resolved := kcsm.Spec.K0s.ResolvedVersion
+	resp.Diagnostics.Append(kcsm.AddK0sStates(ctx, kcc.Spec.Hosts, ra.States)...)
+
+	// without a version, the version channel is looked up again on the next plan, so that the drift is corrected
+	if kcsm.Spec.K0s.Version.IsNull() && !kcsm.Spec.K0s.ResolvedVersion.Equal(resolved) {
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, k0sctlVersionDriftPrivateKey, []byte("true"))...)
+	} else {
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, k0sctlVersionDriftPrivateKey, nil)...)
+	}
+
+	if kc, ok := ra.KubeconfigOut.(*bytes.Buffer); ok && kc.Len() > 0 {
+		resp.Diagnostics.Append(kcsm.AddKubeconfig(ctx, kc)...)
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if diags := resp.State.Set(ctx, kcsm); diags != nil {
+		resp.Diagnostics.Append(diags...)
+	}
 }
 
 func (r *K0sctlConfigResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	var kcsm k0sctlSchemaModel
+	var pkcsm k0sctlSchemaModel // prior state, used to find hosts which were removed from the spec
 	var kcc k0sctl_v1beta1.Cluster
+	var rhs k0sctl_v1beta1_cluster.Hosts
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &kcsm)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &pkcsm)...)
 
 	if resp.Diagnostics.HasError() {
 		return
@@ -151,11 +572,23 @@ func (r *K0sctlConfigResource) Update(ctx context.Context, req resource.UpdateRe
 	if tkcc, ds := kcsm.Cluster(ctx); ds.HasError() {
 		resp.Diagnostics.Append(ds...)
 	} else if err := tkcc.Validate(); err != nil {
-		resp.Diagnostics.Append(diag.NewErrorDiagnostic("k0sctl cluster validation failed", err.Error()))
+		resp.Diagnostics.Append(k0sctlValidationDiagnostics(err)...)
 	} else {
 		kcc = tkcc
 	}
 
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// hosts which were removed from the spec are kept in the cluster, marked to be reset by the apply
+	if pkcc, ds := pkcsm.Cluster(ctx); ds.HasError() {
+		resp.Diagnostics.Append(ds...)
+	} else {
+		rhs = k0sctlRemovedHosts(pkcc.Spec.Hosts, kcc.Spec.Hosts)
+		kcc.Spec.Hosts = append(kcc.Spec.Hosts, rhs...)
+	}
+
 	var pm *k0sctl_phase.Manager
 	var kc io.ReadWriter // will be used to contain kubeconfig, written in the phasemanager, and passed back to the model
 
@@ -164,6 +597,7 @@ func (r *K0sctlConfigResource) Update(ctx context.Context, req resource.UpdateRe
 		resp.Diagnostics.Append(d)
 	} else {
 		pm = tpm
+		pm.DryRun = kcsm.DryRun.ValueBool()
 	}
 
 	if resp.Diagnostics.HasError() {
@@ -173,37 +607,92 @@ func (r *K0sctlConfigResource) Update(ctx context.Context, req resource.UpdateRe
 	kc = bytes.NewBuffer([]byte{})
 
 	aa := provider_action.Apply{
-		Force:         kcsm.Force.ValueBool(),
-		Manager:       pm,
-		KubeconfigOut: kc,
-		//KubeconfigAPIAddress:  kcsm.??
+		Force:                 kcsm.Force.ValueBool(),
+		Manager:               pm,
+		KubeconfigOut:         kc,
+		KubeconfigAPIAddress:  kcsm.APIAddress(kcc),
 		NoWait:                kcsm.NoWait.ValueBool(),
 		NoDrain:               kcsm.NoDrain.ValueBool(),
 		DisableDowngradeCheck: kcsm.DisableDowngradeCheck.ValueBool(),
 		RestoreFrom:           kcsm.RestoreFrom.ValueString(),
 	}
 
+	timeout, diags := kcsm.Timeouts.Update(ctx, k0sctl_default_timeouts["update"])
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	actx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if !pm.DryRun {
+		kcsm.DryRunReport = types.ListNull(k0sctlDryRunReportType)
+	}
+
 	if kcsm.SkipCreate.ValueBool() {
 		resp.Diagnostics.AddWarning("skipping update", "Skipping the k0sctl create because of configuration flag.")
+		resp.Diagnostics.Append(kcsm.AddHostKeys(ctx)...)
+		kcsm.AddResolvedVersion(kcc)
+	} else if pm.DryRun {
+		resp.Diagnostics.Append(r.dryRunApply(actx, &kcsm, aa)...)
+		kcsm.AddResolvedVersion(kcc)
+
+		// nothing changed on the cluster, so the kubeconfig from the last apply is kept
+		kcsm.KubeYaml = pkcsm.KubeYaml
+		kcsm.Kubeconfig = pkcsm.Kubeconfig
+		kcsm.KubeHost = pkcsm.KubeHost
+		kcsm.CaCert = pkcsm.CaCert
+		kcsm.PrivateKey = pkcsm.PrivateKey
+		kcsm.ClientCert = pkcsm.ClientCert
+
+		// the prior state is restored on the next refresh, unless it already holds the state from before an
+		// earlier dry run
+		if b, ds := req.Private.GetKey(ctx, k0sctlDryRunPrivateKey); ds.HasError() {
+			resp.Diagnostics.Append(ds...)
+			return
+		} else if b == nil {
+			resp.Diagnostics.Append(k0sctlSetDryRunPrior(ctx, resp.Private, req.State.Raw)...)
+		}
 	} else if r.testingMode {
 		resp.Diagnostics.AddWarning("testing mode warning", "k0sctl config resource handler is in testing mode, no installation will be run.")
 
 		kcsm.KubeYaml = types.StringNull()
+		kcsm.Kubeconfig = types.ObjectNull(k0sctlKubeconfigType.AttrTypes)
 		kcsm.KubeHost = types.StringNull()
 		kcsm.CaCert = types.StringNull()
 		kcsm.PrivateKey = types.StringNull()
 		kcsm.ClientCert = types.StringNull()
+		resp.Diagnostics.Append(kcsm.AddHostKeys(ctx)...)
+		kcsm.AddResolvedVersion(kcc)
 
 		kcsm.Id = kcsm.Metadata.Name
 
 		if diags := resp.State.Set(ctx, kcsm); diags != nil {
 			resp.Diagnostics.Append(diags...)
 		}
-	} else if err := aa.Run(); err != nil {
+	} else if resp.Diagnostics.Append(kcsm.CheckHostKeys(actx, kcc.Spec.Hosts)...); resp.Diagnostics.HasError() {
+		// nothing was applied, the host keys could not be checked
+	} else if err := aa.Run(actx); err != nil {
+		resp.Diagnostics.Append(k0sctlHostKeyMismatchDiagnostics(err)...)
 		resp.Diagnostics.Append(diag.NewErrorDiagnostic("error running k0sctl apply", err.Error()))
 	} else {
 		// populate the model kubernetes conf from the action
-		resp.Diagnostics.Append(kcsm.AddKubeconfig(kc)...)
+		resp.Diagnostics.Append(kcsm.AddKubeconfig(ctx, kc)...)
+		resp.Diagnostics.Append(kcsm.AddHostKeys(ctx)...)
+		kcsm.AddResolvedVersion(kcc)
+
+		if len(rhs) > 0 {
+			ras := make([]string, len(rhs))
+			for i, h := range rhs {
+				ras[i] = fmt.Sprintf("%s (%s)", h.Address(), h.Role)
+			}
+			if kcsm.NoDrain.ValueBool() {
+				resp.Diagnostics.AddWarning("hosts were reset", fmt.Sprintf("Hosts which were removed from spec.host were reset, without draining them as no_drain is set: %s", strings.Join(ras, ", ")))
+			} else {
+				resp.Diagnostics.AddWarning("hosts were reset", fmt.Sprintf("Hosts which were removed from spec.host were drained and reset: %s", strings.Join(ras, ", ")))
+			}
+		}
 	}
 
 	if resp.Diagnostics.HasError() {
@@ -228,7 +717,7 @@ func (r *K0sctlConfigResource) Delete(ctx context.Context, req resource.DeleteRe
 	if tkcc, ds := kcsm.Cluster(ctx); ds.HasError() {
 		resp.Diagnostics.Append(ds...)
 	} else if err := tkcc.Validate(); err != nil {
-		resp.Diagnostics.Append(diag.NewErrorDiagnostic("k0sctl cluster validation failed", err.Error()))
+		resp.Diagnostics.Append(k0sctlValidationDiagnostics(err)...)
 	} else {
 		kcc = tkcc
 	}
@@ -246,12 +735,19 @@ func (r *K0sctlConfigResource) Delete(ctx context.Context, req resource.DeleteRe
 		return
 	}
 
-	ra := k0sctl_action.Reset{
+	ra := provider_action.Reset{
 		Manager: pm,
-		Force:   true, // k0sctl asks for confirmation if this is not set to true
-		Stdout:  nil,  // TODO: turn this into a tflog outputter?
 	}
 
+	timeout, diags := kcsm.Timeouts.Delete(ctx, k0sctl_default_timeouts["delete"])
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	actx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	if kcsm.SkipDestroy.ValueBool() {
 		resp.Diagnostics.AddWarning("skipping create", "Skipping the k0sctl destroy because of configuration flag.")
 	} else if r.testingMode {
@@ -262,7 +758,8 @@ func (r *K0sctlConfigResource) Delete(ctx context.Context, req resource.DeleteRe
 		if diags := resp.State.Set(ctx, kcsm); diags != nil {
 			resp.Diagnostics.Append(diags...)
 		}
-	} else if err := ra.Run(); err != nil {
+	} else if err := ra.Run(actx); err != nil {
+		resp.Diagnostics.Append(k0sctlHostKeyMismatchDiagnostics(err)...)
 		resp.Diagnostics.Append(diag.NewErrorDiagnostic("error running k0sctl reset", err.Error()))
 		return
 	}
@@ -271,7 +768,31 @@ func (r *K0sctlConfigResource) Delete(ctx context.Context, req resource.DeleteRe
 	resp.State.RemoveResource(ctx)
 }
 
+// ImportState interpret a k0sctl.yaml file, of which the path is the import id, into the resource state.
+//
+// The hosts are not contacted here, the Read that terraform runs after the import gathers the facts and the
+// kubeconfig from the hosts.
 func (r *K0sctlConfigResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// an import is an invalid operation for k0sctl, as it will want to run anyway. Just add the resource and apply it.
-	resp.Diagnostics.AddError("K0sctl imports are invalid", "The k0sctl resource does not support imports, as launchpad itself doesn't maintain state. Just add the resource and hit apply.")
+	var kcsm k0sctlSchemaModel
+
+	kyb, err := os.ReadFile(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Could not read the k0sctl config for import", fmt.Sprintf("The import id must be the path to a k0sctl.yaml file: %s", err.Error()))
+		return
+	}
+
+	kcc, ds := k0sctlClusterFromYaml(kyb)
+	resp.Diagnostics.Append(ds...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(kcsm.FromCluster(ctx, kcc)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, kcsm)...)
 }
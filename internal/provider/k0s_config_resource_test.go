@@ -1,9 +1,14 @@
 package provider
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
 )
 
 func TestAccK0sctlConfigResource(t *testing.T) {
@@ -11,15 +16,57 @@ func TestAccK0sctlConfigResource(t *testing.T) {
 		PreCheck:                 func() { testAccPreCheck(t) },
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
+			// Windows hosts can only be workers
+			{
+				Config:      testAccK0sctlConfigResourceConfig_windowsController(),
+				ExpectError: regexp.MustCompile("Windows hosts can only be workers"),
+			},
+			// a host has a single connection
+			{
+				Config:      testAccK0sctlConfigResourceConfig_conflictingConnections(),
+				ExpectError: regexp.MustCompile("Invalid Attribute Combination"),
+			},
+			{
+				Config:      testAccK0sctlConfigResourceConfig_multipleSSH(),
+				ExpectError: regexp.MustCompile("must contain at most 1 elements"),
+			},
+			// the known values are validated when planning, even if some are only known after apply
+			{
+				Config:      testAccK0sctlConfigResourceConfig_unknownAddress(),
+				ExpectError: regexp.MustCompile("privateAddress"),
+			},
 			// Create and Read testing
 			{
 				Config: testAccK0sctlConfigResourceConfig_minimal(),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.host.0.hooks.0.apply.0.before.0", "ls -la"),
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.host.0.hooks.0.apply.0.after.0", "uptime"),
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.host.0.hooks.0.reset.0.after.0", "umount /mnt/data"),
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.host.0.environment.HTTP_PROXY", "http://proxy.example.org:3128"),
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.host.0.ssh.0.host_key", "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGXIh3Tmw9dMW+PfugPh3abU7JyurDI0Xb7q7IDi6d0S"),
 					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.host.1.install_flags.0", "--taints=mytaint"),
+					resource.TestCheckNoResourceAttr("k0sctl_config.test", "spec.host.1.ssh.0.host_key"),
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.host.1.files.0.dst", "/etc/k0s/containerd.d/proxy.toml"),
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.host.2.winrm.0.bastion.0.address", "jump.example.org"),
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.host.3.openssh.0.address", "dc1-worker1"),
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.host.4.ssh.0.bastion.#", "2"),
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.host.4.ssh.0.bastion.1.address", "dc2-jump.example.org"),
 					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.k0s.version", "0.13"),
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.k0s.resolved_version", "0.13"),
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.k0s.version_channel", "stable"),
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.k0s.dynamic_config", "true"),
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.k0s.config.spec.network.provider", "calico"),
 				),
 			},
+			// the same k0s config, given as yaml, is not a change
+			{
+				Config: testAccK0sctlConfigResourceConfig_yamlK0sConfig(),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
 		},
 	})
 }
@@ -32,7 +79,15 @@ resource "k0sctl_config" "test" {
     }
     spec {
         k0s {
-            version = "0.13"
+            version        = "0.13"
+            dynamic_config = true
+            config = {
+                spec = {
+                    network = {
+                        provider = "calico"
+                    }
+                }
+            }
         }
 
         host {
@@ -41,11 +96,20 @@ resource "k0sctl_config" "test" {
                 address  = "controller1.example.org"
                 key_path = "./key.pem"
                 user     = "ubuntu"
+                host_key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGXIh3Tmw9dMW+PfugPh3abU7JyurDI0Xb7q7IDi6d0S"
+            }
+
+            environment = {
+                HTTP_PROXY = "http://proxy.example.org:3128"
             }
 
             hooks {
                 apply {
                     before = [ "ls -la", "pwd" ]
+                    after  = [ "uptime" ]
+                }
+                reset {
+                    after = [ "umount /mnt/data" ]
                 }
             }
         }
@@ -57,6 +121,15 @@ resource "k0sctl_config" "test" {
                 address  = "worker1.example.org"
                 key_path = "./key.pem"
                 user     = "ubuntu"
+
+                trust_on_first_use = true
+            }
+
+            files {
+                name    = "containerd-proxy"
+                content = "[plugins]"
+                dst     = "/etc/k0s/containerd.d/proxy.toml"
+                perm    = "0644"
             }
         }
 
@@ -66,10 +139,366 @@ resource "k0sctl_config" "test" {
                 address  = "windowsworker1.example.org"
                 user     = "ubuntu"
                 password = "my-win-password"
+
+                bastion {
+                    address  = "jump.example.org"
+                    key_path = "./key.pem"
+                    user     = "jump"
+                }
+            }
+        }
+
+        host {
+            role = "worker"
+            openssh {
+                address = "dc1-worker1"
+                options = {
+                    StrictHostKeyChecking = "yes"
+                }
+            }
+        }
+
+        host {
+            role = "worker"
+            ssh {
+                address  = "10.20.0.11"
+                key_path = "./key.pem"
+                user     = "ubuntu"
+
+                bastion {
+                    address   = "vpn.example.org"
+                    user      = "jump"
+                    use_agent = true
+                }
+                bastion {
+                    address  = "dc2-jump.example.org"
+                    key_path = "./dc2-key.pem"
+                    user     = "jump"
+                }
+            }
+        }
+
+    }
+}
+`
+}
+
+// testAccK0sctlConfigResourceConfig_yamlK0sConfig the minimal config, with the k0s config as a yaml string
+func testAccK0sctlConfigResourceConfig_yamlK0sConfig() string {
+	return strings.Replace(testAccK0sctlConfigResourceConfig_minimal(), `            config = {
+                spec = {
+                    network = {
+                        provider = "calico"
+                    }
+                }
+            }
+`, `            config = <<EOT
+spec: { network: { provider: calico } }
+EOT
+`, 1)
+}
+
+func testAccK0sctlConfigResourceConfig_windowsController() string {
+	return `
+resource "k0sctl_config" "test" {
+    metadata {
+        name = "test"
+    }
+    spec {
+        k0s {
+            version = "0.13"
+        }
+
+        host {
+            role = "controller"
+            winrm {
+                address  = "windowscontroller1.example.org"
+                user     = "Administrator"
+                password = "my-win-password"
+            }
+        }
+    }
+}
+`
+}
+
+func testAccK0sctlConfigResourceConfig_conflictingConnections() string {
+	return `
+resource "k0sctl_config" "test" {
+    metadata {
+        name = "test"
+    }
+    spec {
+        k0s {
+            version = "0.13"
+        }
+
+        host {
+            role = "single"
+            ssh {
+                address = "controller1.example.org"
+                user    = "ubuntu"
             }
+            localhost {}
         }
+    }
+}
+`
+}
 
+func testAccK0sctlConfigResourceConfig_multipleSSH() string {
+	return `
+resource "k0sctl_config" "test" {
+    metadata {
+        name = "test"
+    }
+    spec {
+        k0s {
+            version = "0.13"
+        }
+
+        host {
+            role = "controller"
+            ssh {
+                address = "controller1.example.org"
+                user    = "ubuntu"
+            }
+            ssh {
+                address = "controller2.example.org"
+                user    = "ubuntu"
+            }
+        }
+    }
+}
+`
+}
+
+func testAccK0sctlConfigResourceConfig_unknownAddress() string {
+	return `
+resource "terraform_data" "address" {
+    input = "controller1.example.org"
+}
+
+resource "k0sctl_config" "test" {
+    metadata {
+        name = "test"
+    }
+    spec {
+        k0s {
+            version = "0.13"
+        }
+
+        host {
+            role            = "controller"
+            private_address = "not-an-ip"
+            ssh {
+                address  = terraform_data.address.output
+                key_path = "./key.pem"
+                user     = "ubuntu"
+            }
+        }
     }
 }
 `
 }
+
+func TestAccK0sctlConfigResource_upgrade(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccK0sctlConfigResourceConfig_version("v1.29.6+k0s.0", false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.k0s.resolved_version", "v1.29.6+k0s.0"),
+				),
+			},
+			// kubernetes minor versions can't be skipped
+			{
+				Config:      testAccK0sctlConfigResourceConfig_version("v1.31.1+k0s.0", false),
+				ExpectError: regexp.MustCompile("skips a Kubernetes minor version"),
+			},
+			// downgrades need the downgrade check to be disabled
+			{
+				Config:      testAccK0sctlConfigResourceConfig_version("v1.29.5+k0s.0", false),
+				ExpectError: regexp.MustCompile("K0s downgrade"),
+			},
+			{
+				Config: testAccK0sctlConfigResourceConfig_version("v1.29.5+k0s.0", true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.k0s.resolved_version", "v1.29.5+k0s.0"),
+				),
+			},
+			{
+				Config: testAccK0sctlConfigResourceConfig_version("v1.30.2+k0s.0", false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.k0s.resolved_version", "v1.30.2+k0s.0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccK0sctlConfigResourceConfig_version(version string, disableDowngradeCheck bool) string {
+	return fmt.Sprintf(`
+resource "k0sctl_config" "test" {
+    disable_downgrade_check = %t
+
+    metadata {
+        name = "test"
+    }
+    spec {
+        k0s {
+            version = %q
+        }
+
+        host {
+            role = "controller"
+            ssh {
+                address  = "controller1.example.org"
+                key_path = "./key.pem"
+                user     = "ubuntu"
+            }
+        }
+        host {
+            role = "worker"
+            ssh {
+                address  = "worker1.example.org"
+                key_path = "./key.pem"
+                user     = "ubuntu"
+            }
+        }
+    }
+}
+`, disableDowngradeCheck, version)
+}
+
+func TestAccK0sctlConfigResource_replacedAddress(t *testing.T) {
+	address := tfjsonpath.New("spec").AtMapKey("host").AtSliceIndex(0).AtMapKey("ssh").AtSliceIndex(0).AtMapKey("address")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccK0sctlConfigResourceConfig_replacedAddress("1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.host.0.ssh.0.address", "controller1.example.org"),
+				),
+			},
+			// an address which is only known after the replacement of another resource is a change
+			{
+				Config: testAccK0sctlConfigResourceConfig_replacedAddress("2"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("k0sctl_config.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectUnknownValue("k0sctl_config.test", address),
+					},
+				},
+			},
+		},
+	})
+}
+
+func testAccK0sctlConfigResourceConfig_replacedAddress(revision string) string {
+	return fmt.Sprintf(`
+resource "terraform_data" "address" {
+    input            = "controller1.example.org"
+    triggers_replace = %q
+}
+
+resource "k0sctl_config" "test" {
+    metadata {
+        name = "test"
+    }
+    spec {
+        k0s {
+            version = "v1.29.6+k0s.0"
+        }
+
+        host {
+            role = "single"
+            ssh {
+                address  = terraform_data.address.output
+                key_path = "./key.pem"
+                user     = "ubuntu"
+            }
+        }
+    }
+}
+`, revision)
+}
+
+func TestAccK0sctlConfigResource_dryRun(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// a dry run create installs nothing, so the resource is created again
+			{
+				Config: testAccK0sctlConfigResourceConfig_dryRun("v1.29.6+k0s.0", true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("k0sctl_config.test", "dry_run", "true"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccK0sctlConfigResourceConfig_dryRun("v1.29.6+k0s.0", false),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("k0sctl_config.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("k0sctl_config.test", "dry_run_report.#"),
+				),
+			},
+			// a dry run update changes nothing, so the prior state is restored and the update is planned again
+			{
+				Config: testAccK0sctlConfigResourceConfig_dryRun("v1.30.2+k0s.0", true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.k0s.resolved_version", "v1.30.2+k0s.0"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccK0sctlConfigResourceConfig_dryRun("v1.30.2+k0s.0", false),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("k0sctl_config.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("k0sctl_config.test", "spec.k0s.resolved_version", "v1.30.2+k0s.0"),
+					resource.TestCheckResourceAttr("k0sctl_config.test", "dry_run", "false"),
+				),
+			},
+		},
+	})
+}
+
+func testAccK0sctlConfigResourceConfig_dryRun(version string, dryRun bool) string {
+	return fmt.Sprintf(`
+resource "k0sctl_config" "test" {
+    dry_run = %t
+
+    metadata {
+        name = "test"
+    }
+    spec {
+        k0s {
+            version = %q
+        }
+
+        host {
+            role = "controller"
+            ssh {
+                address  = "controller1.example.org"
+                key_path = "./key.pem"
+                user     = "ubuntu"
+            }
+        }
+    }
+}
+`, dryRun, version)
+}
@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	k0sctl_phase "github.com/k0sproject/k0sctl/phase"
+
+	provider_action "github.com/mirantis/terraform-provider-k0sctl/internal/k0sctl/action"
+)
+
+var _ datasource.DataSource = &K0sctlKubeconfigDataSource{}
+
+type K0sctlKubeconfigDataSource struct {
+	testingMode bool
+}
+
+func NewK0sctlKubeconfigDataSource() datasource.DataSource {
+	return &K0sctlKubeconfigDataSource{}
+}
+
+func (d *K0sctlKubeconfigDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kubeconfig"
+}
+
+func (d *K0sctlKubeconfigDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = k0sctl_kubeconfig_data_source_schema()
+}
+
+func (d *K0sctlKubeconfigDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	kpm, ok := req.ProviderData.(*K0sctlProviderModel)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *K0sctlProviderModel, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.testingMode = kpm.testingMode
+}
+
+// Read connect to the controllers and fetch the admin kubeconfig.
+func (d *K0sctlKubeconfigDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var kksm k0sctlKubeconfigSchemaModel
+
+	resp.Diagnostics.Append(kksm.FromConfig(ctx, req.Config)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if d.testingMode {
+		resp.Diagnostics.AddWarning("testing mode warning", "k0sctl kubeconfig data source handler is in testing mode, no kubeconfig will be retrieved.")
+		kksm.NullCredentials()
+		resp.Diagnostics.Append(resp.State.Set(ctx, kksm)...)
+		return
+	}
+
+	resp.Diagnostics.Append(kksm.Fetch(ctx)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, kksm)...)
+}
+
+// FromConfig read the model from the data source or ephemeral resource config.
+//
+// Neither have defaults, so the defaults of the host blocks, which are shared with the resources, are applied first.
+func (kksm *k0sctlKubeconfigSchemaModel) FromConfig(ctx context.Context, config tfsdk.Config) diag.Diagnostics {
+	var d diag.Diagnostics
+
+	raw, err := k0sctlApplyDefaults(ctx, k0sctl_kubeconfig_data_source_shared_schema(), config.Raw)
+	if err != nil {
+		d.AddError("Could not apply the host defaults", err.Error())
+		return d
+	}
+
+	d.Append(tfsdk.Config{Raw: raw, Schema: config.Schema}.Get(ctx, kksm)...)
+
+	if d.HasError() {
+		return d
+	}
+
+	d.Append(kksm.Spec.AddHostKeys(ctx)...)
+	kksm.Id = kksm.Metadata.Name
+
+	return d
+}
+
+// NullCredentials set the kubeconfig values to null, when no kubeconfig is fetched.
+func (kksm *k0sctlKubeconfigSchemaModel) NullCredentials() {
+	kksm.KubeYaml = types.StringNull()
+	kksm.KubeHost = types.StringNull()
+	kksm.PrivateKey = types.StringNull()
+	kksm.ClientCert = types.StringNull()
+	kksm.CaCert = types.StringNull()
+}
+
+// Fetch connect to the controllers and fetch the admin kubeconfig into the model.
+func (kksm *k0sctlKubeconfigSchemaModel) Fetch(ctx context.Context) diag.Diagnostics {
+	kcc, d := kksm.Spec.Cluster(ctx, kksm.Metadata.Name.ValueString())
+
+	if d.HasError() {
+		return d
+	}
+
+	pm, err := k0sctl_phase.NewManager(&kcc)
+	if err != nil {
+		d.Append(diag.NewErrorDiagnostic("k0sctl phase manager creation failed", err.Error()))
+		return d
+	}
+
+	kubeconfigBuffer := bytes.NewBuffer(nil)
+
+	ka := provider_action.Kubeconfig{
+		Manager:    pm,
+		APIAddress: kksm.APIAddress.ValueString(),
+		Out:        kubeconfigBuffer,
+	}
+
+	if err := ka.Run(ctx); err != nil {
+		d.Append(diag.NewErrorDiagnostic("error retrieving kubeconfig with k0sctl", err.Error()))
+		return d
+	}
+
+	kksm.KubeYaml = types.StringValue(kubeconfigBuffer.String())
+
+	kc, kcds := k0sctlKubeconfigParse(kubeconfigBuffer.Bytes())
+	d.Append(kcds...)
+
+	if d.HasError() {
+		return d
+	}
+
+	kcreds := kc.Current()
+	kksm.KubeHost = kcreds.Host
+	kksm.CaCert = kcreds.CaCert
+	kksm.PrivateKey = kcreds.PrivateKey
+	kksm.ClientCert = kcreds.ClientCert
+
+	return d
+}
@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccK0sctlKubeconfigDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: testAccK0sctlKubeconfigDataSourceConfig_minimal(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.k0sctl_kubeconfig.test", "id", "test"),
+					resource.TestCheckResourceAttr("data.k0sctl_kubeconfig.test", "api_address", "k8s.example.org"),
+					resource.TestCheckResourceAttr("data.k0sctl_kubeconfig.test", "spec.host.0.ssh.0.port", "22"),
+				),
+			},
+		},
+	})
+}
+
+func testAccK0sctlKubeconfigDataSourceConfig_minimal() string {
+	return `
+data "k0sctl_kubeconfig" "test" {
+    api_address = "k8s.example.org"
+
+    metadata {
+        name = "test"
+    }
+
+    spec {
+        host {
+            role = "controller"
+
+            ssh {
+                address  = "controller1.example.org"
+                user     = "ubuntu"
+                key_path = "./key.pem"
+            }
+        }
+    }
+}
+`
+}
@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+)
+
+var _ ephemeral.EphemeralResourceWithConfigure = &K0sctlKubeconfigEphemeralResource{}
+
+type K0sctlKubeconfigEphemeralResource struct {
+	testingMode bool
+}
+
+func NewK0sctlKubeconfigEphemeralResource() ephemeral.EphemeralResource {
+	return &K0sctlKubeconfigEphemeralResource{}
+}
+
+func (r *K0sctlKubeconfigEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kubeconfig"
+}
+
+func (r *K0sctlKubeconfigEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = k0sctl_kubeconfig_ephemeral_resource_schema()
+}
+
+func (r *K0sctlKubeconfigEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	kpm, ok := req.ProviderData.(*K0sctlProviderModel)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *K0sctlProviderModel, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.testingMode = kpm.testingMode
+}
+
+// Open connect to the controllers and fetch the admin kubeconfig, which terraform keeps out of the plan and state.
+func (r *K0sctlKubeconfigEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var kksm k0sctlKubeconfigSchemaModel
+
+	resp.Diagnostics.Append(kksm.FromConfig(ctx, req.Config)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.testingMode {
+		resp.Diagnostics.AddWarning("testing mode warning", "k0sctl kubeconfig ephemeral resource handler is in testing mode, no kubeconfig will be retrieved.")
+		kksm.NullCredentials()
+		resp.Diagnostics.Append(resp.Result.Set(ctx, kksm)...)
+		return
+	}
+
+	resp.Diagnostics.Append(kksm.Fetch(ctx)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, kksm)...)
+}
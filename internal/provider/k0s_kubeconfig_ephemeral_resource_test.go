@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/echoprovider"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccK0sctlKubeconfigEphemeralResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		// ephemeral resources are only supported by terraform 1.10 and later
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"k0sctl": testAccProtoV6ProviderFactories["k0sctl"],
+			"echo":   echoprovider.NewProviderServer(),
+		},
+		Steps: []resource.TestStep{
+			// Open testing, the ephemeral values are passed through the echo provider to be checked
+			{
+				Config: testAccK0sctlKubeconfigEphemeralResourceConfig_minimal(),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("id"), knownvalue.StringExact("test")),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("api_address"), knownvalue.StringExact("k8s.example.org")),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("kube_yaml"), knownvalue.Null()),
+				},
+			},
+		},
+	})
+}
+
+func testAccK0sctlKubeconfigEphemeralResourceConfig_minimal() string {
+	return `
+ephemeral "k0sctl_kubeconfig" "test" {
+    api_address = "k8s.example.org"
+
+    metadata {
+        name = "test"
+    }
+
+    spec {
+        host {
+            role = "controller"
+
+            ssh {
+                address  = "controller1.example.org"
+                user     = "ubuntu"
+                key_path = "./key.pem"
+            }
+        }
+    }
+}
+
+provider "echo" {
+    data = ephemeral.k0sctl_kubeconfig.test
+}
+
+resource "echo" "test" {}
+`
+}
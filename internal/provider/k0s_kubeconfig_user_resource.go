@@ -0,0 +1,191 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	k0sctl_phase "github.com/k0sproject/k0sctl/phase"
+
+	provider_action "github.com/mirantis/terraform-provider-k0sctl/internal/k0sctl/action"
+)
+
+var _ resource.Resource = &K0sctlKubeconfigUserResource{}
+
+type K0sctlKubeconfigUserResource struct {
+	testingMode bool
+}
+
+func NewK0sctlKubeconfigUserResource() resource.Resource {
+	return &K0sctlKubeconfigUserResource{}
+}
+
+func (r *K0sctlKubeconfigUserResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kubeconfig_user"
+}
+
+func (r *K0sctlKubeconfigUserResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = k0sctl_kubeconfig_user_schema()
+}
+
+func (r *K0sctlKubeconfigUserResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	kpm, ok := req.ProviderData.(*K0sctlProviderModel)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *K0sctlProviderModel, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.testingMode = kpm.testingMode
+}
+
+func (r *K0sctlKubeconfigUserResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var kusm k0sctlKubeconfigUserSchemaModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &kusm)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var groups []string
+	resp.Diagnostics.Append(kusm.Groups.ElementsAs(ctx, &groups, false)...)
+
+	kcc, ds := k0sctlClusterHosts(ctx, kusm.Hosts, "k0s-kubeconfig-user")
+	resp.Diagnostics.Append(ds...)
+
+	var pm *k0sctl_phase.Manager
+
+	if tpm, err := k0sctl_phase.NewManager(&kcc); err != nil {
+		d := diag.NewErrorDiagnostic("k0sctl phase manager creation failed", err.Error())
+		resp.Diagnostics.Append(d)
+	} else {
+		pm = tpm
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	kusm.Id = kusm.User
+	kusm.KubeYaml = types.StringNull()
+	kusm.KubeHost = types.StringNull()
+	kusm.PrivateKey = types.StringNull()
+	kusm.ClientCert = types.StringNull()
+	kusm.CaCert = types.StringNull()
+	kusm.ExpiresAt = types.StringNull()
+
+	if r.testingMode {
+		resp.Diagnostics.AddWarning("testing mode warning", "k0sctl kubeconfig user resource handler is in testing mode, no kubeconfig will be created.")
+		resp.Diagnostics.Append(resp.State.Set(ctx, kusm)...)
+		return
+	}
+
+	kubeconfigBuffer := bytes.NewBuffer(nil)
+
+	ka := provider_action.KubeconfigUser{
+		Manager: pm,
+		User:    kusm.User.ValueString(),
+		Groups:  groups,
+		Out:     kubeconfigBuffer,
+	}
+
+	if err := ka.Run(ctx); err != nil {
+		resp.Diagnostics.Append(diag.NewErrorDiagnostic("error creating user kubeconfig with k0s", err.Error()))
+		return
+	}
+
+	kc, kcds := k0sctlKubeconfigParse(kubeconfigBuffer.Bytes())
+	resp.Diagnostics.Append(kcds...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	kcreds := kc.Current()
+	kusm.KubeYaml = types.StringValue(kubeconfigBuffer.String())
+	kusm.KubeHost = kcreds.Host
+	kusm.CaCert = kcreds.CaCert
+	kusm.PrivateKey = kcreds.PrivateKey
+	kusm.ClientCert = kcreds.ClientCert
+
+	if na, err := k0sctlCertificateNotAfter(kcreds.ClientCert.ValueString()); err != nil {
+		resp.Diagnostics.AddWarning("Could not determine the client certificate expiry", err.Error())
+	} else {
+		kusm.ExpiresAt = types.StringValue(na.Format(time.RFC3339))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, kusm)...)
+}
+
+// Read check that the client certificate has not expired, so that an expired kubeconfig is created again.
+func (r *K0sctlKubeconfigUserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var kusm k0sctlKubeconfigUserSchemaModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &kusm)...)
+
+	if resp.Diagnostics.HasError() || kusm.ExpiresAt.IsNull() {
+		return
+	}
+
+	ea, err := time.Parse(time.RFC3339, kusm.ExpiresAt.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeWarning(path.Root("expires_at"), "Could not interpret the client certificate expiry", err.Error())
+		return
+	}
+
+	if time.Now().After(ea) {
+		tflog.Warn(ctx, "k0s user kubeconfig has expired", map[string]interface{}{"user": kusm.User.ValueString(), "expires_at": kusm.ExpiresAt.ValueString()})
+		resp.State.RemoveResource(ctx)
+	}
+}
+
+func (r *K0sctlKubeconfigUserResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// the user and groups require replacement, and the computed values are kept from state
+	var kusm k0sctlKubeconfigUserSchemaModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &kusm)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, kusm)...)
+}
+
+// Delete remove the kubeconfig from state. The client certificate can't be revoked, it stays valid until it expires.
+func (r *K0sctlKubeconfigUserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.State.RemoveResource(ctx)
+}
+
+// k0sctlCertificateNotAfter the expiry time of a PEM encoded certificate.
+func k0sctlCertificateNotAfter(certPEM string) (time.Time, error) {
+	b, _ := pem.Decode([]byte(certPEM))
+	if b == nil {
+		return time.Time{}, fmt.Errorf("no PEM certificate found")
+	}
+
+	c, err := x509.ParseCertificate(b.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return c.NotAfter, nil
+}
@@ -0,0 +1,35 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccK0sctlKubeconfigUserResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccK0sctlKubeconfigUserResourceConfig_minimal(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("k0sctl_kubeconfig_user.test", "id", "ci"),
+					resource.TestCheckResourceAttr("k0sctl_kubeconfig_user.test", "groups.0", "ci-deployers"),
+					resource.TestCheckResourceAttr("k0sctl_kubeconfig_user.test", "hosts.0.ssh.0.address", "controller1.example.org"),
+				),
+			},
+		},
+	})
+}
+
+func testAccK0sctlKubeconfigUserResourceConfig_minimal() string {
+	return testAccK0sctlConfigResourceConfig_minimal() + `
+resource "k0sctl_kubeconfig_user" "test" {
+    user   = "ci"
+    groups = ["ci-deployers"]
+    hosts  = k0sctl_config.test.spec.host
+}
+`
+}
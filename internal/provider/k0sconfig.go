@@ -0,0 +1,268 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+
+	k0s_dig "github.com/k0sproject/dig"
+)
+
+// errK0sctlK0sConfigUnknown the k0s config contains values which are only known after apply.
+var errK0sctlK0sConfigUnknown = errors.New("the k0s config is not known until apply")
+
+// k0sctlK0sConfigMapping the k0s config, given either as a yaml string or as an object, as a k0s dig.Mapping.
+//
+// A null config is an empty mapping.
+func k0sctlK0sConfigMapping(config types.Dynamic) (k0s_dig.Mapping, error) {
+	if config.IsNull() || config.IsUnderlyingValueNull() {
+		return nil, nil
+	}
+	if config.IsUnknown() || config.IsUnderlyingValueUnknown() {
+		return nil, errK0sctlK0sConfigUnknown
+	}
+
+	var kcb []byte
+
+	if s, ok := config.UnderlyingValue().(types.String); ok {
+		kcb = []byte(s.ValueString())
+	} else {
+		// objects go through yaml as well, so that nested maps are converted to dig mappings the same way
+		v, err := k0sctlAttrValueInterface(config.UnderlyingValue())
+		if err != nil {
+			return nil, err
+		}
+
+		if b, err := yaml.Marshal(v); err != nil {
+			return nil, err
+		} else {
+			kcb = b
+		}
+	}
+
+	var dm k0s_dig.Mapping
+	if err := yaml.UnmarshalStrict(kcb, &dm); err != nil {
+		return nil, err
+	}
+
+	return dm, nil
+}
+
+// k0sctlAttrValueInterface convert a terraform value to plain go values which can be marshalled to yaml.
+//
+// Null object attributes and map elements are left out.
+func k0sctlAttrValueInterface(v attr.Value) (interface{}, error) {
+	if v.IsNull() {
+		return nil, nil
+	}
+	if v.IsUnknown() {
+		return nil, errK0sctlK0sConfigUnknown
+	}
+
+	elements := func(evs []attr.Value) ([]interface{}, error) {
+		l := make([]interface{}, len(evs))
+		for i, ev := range evs {
+			iv, err := k0sctlAttrValueInterface(ev)
+			if err != nil {
+				return nil, err
+			}
+			l[i] = iv
+		}
+		return l, nil
+	}
+
+	attributes := func(avs map[string]attr.Value) (map[string]interface{}, error) {
+		m := map[string]interface{}{}
+		for k, av := range avs {
+			if av.IsNull() {
+				continue
+			}
+			iv, err := k0sctlAttrValueInterface(av)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = iv
+		}
+		return m, nil
+	}
+
+	switch tv := v.(type) {
+	case types.Dynamic:
+		return k0sctlAttrValueInterface(tv.UnderlyingValue())
+	case types.String:
+		return tv.ValueString(), nil
+	case types.Bool:
+		return tv.ValueBool(), nil
+	case types.Int64:
+		return tv.ValueInt64(), nil
+	case types.Float64:
+		return tv.ValueFloat64(), nil
+	case types.Number:
+		bf := tv.ValueBigFloat()
+		if i, acc := bf.Int64(); bf.IsInt() && acc == big.Exact {
+			return i, nil
+		}
+		f, _ := bf.Float64()
+		return f, nil
+	case types.Object:
+		return attributes(tv.Attributes())
+	case types.Map:
+		return attributes(tv.Elements())
+	case types.Tuple:
+		return elements(tv.Elements())
+	case types.List:
+		return elements(tv.Elements())
+	case types.Set:
+		return elements(tv.Elements())
+	}
+
+	return nil, fmt.Errorf("unsupported k0s config value %s", v.String())
+}
+
+// k0sctlK0sConfigSemanticEquality keep the prior k0s config when the configured one only differs in its
+// formatting (e.g. yaml whitespace, key order, or a yaml string replaced by the same object).
+//
+// The config is computed, so that the prior value can be planned instead of the configured one. It is only
+// computed from the configuration, an empty config stays empty.
+func k0sctlK0sConfigSemanticEquality() planmodifier.Dynamic {
+	return k0sctlK0sConfigSemanticEqualityModifier{}
+}
+
+type k0sctlK0sConfigSemanticEqualityModifier struct{}
+
+func (m k0sctlK0sConfigSemanticEqualityModifier) Description(_ context.Context) string {
+	return "Keeps the prior k0s config if the configured one is equivalent, and plans no config if none is configured."
+}
+
+func (m k0sctlK0sConfigSemanticEqualityModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m k0sctlK0sConfigSemanticEqualityModifier) PlanModifyDynamic(ctx context.Context, req planmodifier.DynamicRequest, resp *planmodifier.DynamicResponse) {
+	if req.ConfigValue.IsNull() {
+		resp.PlanValue = types.DynamicNull()
+		return
+	}
+	if req.StateValue.IsNull() || req.ConfigValue.Equal(req.StateValue) {
+		return
+	}
+
+	sn, err := k0sctlK0sConfigNormalized(req.StateValue)
+	if err != nil {
+		return
+	}
+	pn, err := k0sctlK0sConfigNormalized(req.ConfigValue)
+	if err != nil {
+		return
+	}
+
+	if reflect.DeepEqual(sn, pn) {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+// k0sctlK0sConfigNormalized the k0s config, given either as a yaml string or as an object, as plain json values.
+//
+// The yaml and terraform numbers decode to different go types (e.g. int, float64 or a big.Float), and the dig
+// mappings turn floats into strings, so the values go through json to compare the same numbers equal.
+func k0sctlK0sConfigNormalized(config types.Dynamic) (interface{}, error) {
+	if config.IsNull() || config.IsUnderlyingValueNull() {
+		return nil, nil
+	}
+	if config.IsUnknown() || config.IsUnderlyingValueUnknown() {
+		return nil, errK0sctlK0sConfigUnknown
+	}
+
+	var v interface{}
+
+	if s, ok := config.UnderlyingValue().(types.String); ok {
+		var yv interface{}
+		if err := yaml.UnmarshalStrict([]byte(s.ValueString()), &yv); err != nil {
+			return nil, err
+		}
+		v = k0sctlYamlJSONValue(yv)
+	} else {
+		av, err := k0sctlAttrValueInterface(config.UnderlyingValue())
+		if err != nil {
+			return nil, err
+		}
+		v = av
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var n interface{}
+	if err := json.Unmarshal(b, &n); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// k0sctlYamlJSONValue convert the generic maps of a yaml decoding to maps with string keys, which json can encode.
+func k0sctlYamlJSONValue(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(tv))
+		for k, kv := range tv {
+			m[fmt.Sprint(k)] = k0sctlYamlJSONValue(kv)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(tv))
+		for i, lv := range tv {
+			l[i] = k0sctlYamlJSONValue(lv)
+		}
+		return l
+	}
+	return v
+}
+
+// k0sctlUpgradeStateV0 upgrade the state from schema version 0, where spec.k0s.config was a yaml string.
+//
+// The state is changed as json, as the prior schema is otherwise identical, and a dynamic value is stored with
+// its type.
+func k0sctlUpgradeStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	var rs map[string]interface{}
+
+	dec := json.NewDecoder(bytes.NewReader(req.RawState.JSON))
+	dec.UseNumber()
+	if err := dec.Decode(&rs); err != nil {
+		resp.Diagnostics.AddError("Could not read the prior k0sctl_config state", err.Error())
+		return
+	}
+
+	if spec, ok := rs["spec"].(map[string]interface{}); ok {
+		if k0s, ok := spec["k0s"].(map[string]interface{}); ok {
+			if c, ok := k0s["config"].(string); ok {
+				k0s["config"] = map[string]interface{}{
+					"value": c,
+					"type":  "string",
+				}
+			}
+		}
+	}
+
+	rsb, err := json.Marshal(rs)
+	if err != nil {
+		resp.Diagnostics.AddError("Could not upgrade the prior k0sctl_config state", err.Error())
+		return
+	}
+
+	resp.DynamicValue = &tfprotov6.DynamicValue{JSON: rsb}
+}
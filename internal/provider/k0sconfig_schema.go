@@ -0,0 +1,322 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	k0sversion "github.com/k0sproject/version"
+)
+
+const (
+	// k0sctlK0sConfigSchemaTimeout how long the download of the ClusterConfig schema of a k0s version may take.
+	k0sctlK0sConfigSchemaTimeout = 30 * time.Second
+
+	// k0sctlK0sConfigSchemaPath the path of the ClusterConfig CRD in the k0s sources of a release.
+	k0sctlK0sConfigSchemaPath = "static/_crds/k0s/k0s.k0sproject.io_clusterconfigs.yaml"
+)
+
+// k0sctlK0sConfigSchemas the downloaded ClusterConfig schemas by url, which don't change for a k0s release.
+var k0sctlK0sConfigSchemas sync.Map
+
+// k0sctlK0sConfigSchema the OpenAPI v3 schema of a field of the k0s ClusterConfig, as published in its CRD.
+//
+// Only the parts of the schema which k0s generates for its API types are checked.
+type k0sctlK0sConfigSchema struct {
+	Type                  string                               `yaml:"type"`
+	Format                string                               `yaml:"format"`
+	Pattern               string                               `yaml:"pattern"`
+	Enum                  []interface{}                        `yaml:"enum"`
+	Minimum               *float64                             `yaml:"minimum"`
+	Maximum               *float64                             `yaml:"maximum"`
+	Properties            map[string]*k0sctlK0sConfigSchema    `yaml:"properties"`
+	AdditionalProperties  *k0sctlK0sConfigAdditionalProperties `yaml:"additionalProperties"`
+	Items                 *k0sctlK0sConfigSchema               `yaml:"items"`
+	IntOrString           bool                                 `yaml:"x-kubernetes-int-or-string"`
+	PreserveUnknownFields bool                                 `yaml:"x-kubernetes-preserve-unknown-fields"`
+}
+
+// k0sctlK0sConfigAdditionalProperties the additionalProperties of an object schema, either a boolean or the schema
+// of the values of a map.
+type k0sctlK0sConfigAdditionalProperties struct {
+	Allowed bool
+	Schema  *k0sctlK0sConfigSchema
+}
+
+func (ap *k0sctlK0sConfigAdditionalProperties) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var b bool
+	if err := unmarshal(&b); err == nil {
+		ap.Allowed = b
+		return nil
+	}
+
+	ap.Allowed = true
+	ap.Schema = &k0sctlK0sConfigSchema{}
+	return unmarshal(ap.Schema)
+}
+
+// k0sctlK0sConfigCRD the parts of the ClusterConfig CRD which hold the schema.
+type k0sctlK0sConfigCRD struct {
+	Spec struct {
+		Versions []struct {
+			Name   string `yaml:"name"`
+			Schema struct {
+				OpenAPIV3Schema *k0sctlK0sConfigSchema `yaml:"openAPIV3Schema"`
+			} `yaml:"schema"`
+		} `yaml:"versions"`
+	} `yaml:"spec"`
+}
+
+// k0sctlK0sConfigSchemaURL the url of the ClusterConfig CRD of a k0s version, under the sources endpoint.
+func k0sctlK0sConfigSchemaURL(endpoint string, v *k0sversion.Version) string {
+	return strings.TrimSuffix(endpoint, "/") + "/" + url.PathEscape(v.String()) + "/" + k0sctlK0sConfigSchemaPath
+}
+
+// k0sctlK0sConfigSchemaDownload download the ClusterConfig v1beta1 schema of a k0s version, from the CRD in the k0s
+// sources of the release, so that the config is validated against the API types of the version which is installed.
+func k0sctlK0sConfigSchemaDownload(ctx context.Context, endpoint string, v *k0sversion.Version) (*k0sctlK0sConfigSchema, error) {
+	u := k0sctlK0sConfigSchemaURL(endpoint, v)
+
+	if s, ok := k0sctlK0sConfigSchemas.Load(u); ok {
+		return s.(*k0sctlK0sConfigSchema), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, k0sctlK0sConfigSchemaTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with %s", u, res.Status)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(res.Body, 4<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	var crd k0sctlK0sConfigCRD
+	if err := yaml.Unmarshal(b, &crd); err != nil {
+		return nil, fmt.Errorf("%s is not a ClusterConfig CRD: %w", u, err)
+	}
+
+	for _, cv := range crd.Spec.Versions {
+		if cv.Name == "v1beta1" && cv.Schema.OpenAPIV3Schema != nil {
+			k0sctlK0sConfigSchemas.Store(u, cv.Schema.OpenAPIV3Schema)
+			return cv.Schema.OpenAPIV3Schema, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s has no ClusterConfig v1beta1 schema", u)
+}
+
+// k0sctlK0sConfigError an error in a k0s config, at the yaml path of the field.
+//
+// Unknown fields are only reported as warnings, k0s ignores the fields which it doesn't know.
+type k0sctlK0sConfigError struct {
+	Path    string
+	Err     error
+	Unknown bool
+}
+
+func (e k0sctlK0sConfigError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Err)
+}
+
+// Validate validate a k0s config, as plain json values, against the schema. No config (nil) is valid, k0s uses its
+// defaults.
+//
+// The required fields are not checked, k0s fills in the defaults of the fields which are not given.
+func (s *k0sctlK0sConfigSchema) Validate(v interface{}) []k0sctlK0sConfigError {
+	errs := []k0sctlK0sConfigError{}
+	s.validate(v, []string{}, &errs)
+	return errs
+}
+
+func (s *k0sctlK0sConfigSchema) validate(v interface{}, yp []string, errs *[]k0sctlK0sConfigError) {
+	if v == nil {
+		return
+	}
+
+	fail := func(err error) {
+		*errs = append(*errs, k0sctlK0sConfigError{Path: k0sctlK0sConfigPath(yp), Err: err})
+	}
+
+	if s.IntOrString {
+		if _, ok := v.(string); !ok && !k0sctlK0sConfigIsInteger(v) {
+			fail(fmt.Errorf("expected an integer or a string, got %#v", v))
+		}
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			fail(fmt.Errorf("expected an object, got %#v", v))
+			return
+		}
+
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			kyp := append(append([]string{}, yp...), k)
+
+			if ps, ok := s.Properties[k]; ok {
+				ps.validate(m[k], kyp, errs)
+			} else if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+				s.AdditionalProperties.Schema.validate(m[k], kyp, errs)
+			} else if !s.PreserveUnknownFields && len(s.Properties) > 0 && (s.AdditionalProperties == nil || !s.AdditionalProperties.Allowed) {
+				*errs = append(*errs, k0sctlK0sConfigError{Path: k0sctlK0sConfigPath(kyp), Err: fmt.Errorf("unknown field, expected one of %s", k0sctlK0sConfigFieldNames(s.Properties)), Unknown: true})
+			}
+		}
+		return
+	case "array":
+		l, ok := v.([]interface{})
+		if !ok {
+			fail(fmt.Errorf("expected a list, got %#v", v))
+			return
+		}
+
+		if s.Items != nil {
+			for i, iv := range l {
+				s.Items.validate(iv, append(append([]string{}, yp...), fmt.Sprintf("[%d]", i)), errs)
+			}
+		}
+		return
+	case "string":
+		sv, ok := v.(string)
+		if !ok {
+			fail(fmt.Errorf("expected a string, got %#v", v))
+			return
+		}
+		if err := k0sctlK0sConfigCheckFormat(s.Format, sv); err != nil {
+			fail(err)
+			return
+		}
+		if re, err := regexp.Compile(s.Pattern); s.Pattern != "" && err == nil && !re.MatchString(sv) {
+			fail(fmt.Errorf("%q does not match %s", sv, s.Pattern))
+			return
+		}
+	case "integer", "number":
+		f, ok := v.(float64)
+		if !ok {
+			fail(fmt.Errorf("expected a number, got %#v", v))
+			return
+		}
+		if s.Type == "integer" && !k0sctlK0sConfigIsInteger(v) {
+			fail(fmt.Errorf("expected an integer, got %v", f))
+			return
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			fail(fmt.Errorf("%v is less than the minimum %v", f, *s.Minimum))
+			return
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			fail(fmt.Errorf("%v is more than the maximum %v", f, *s.Maximum))
+			return
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail(fmt.Errorf("expected a boolean, got %#v", v))
+			return
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		allowed := make([]string, len(s.Enum))
+		for i, e := range s.Enum {
+			allowed[i] = fmt.Sprint(e)
+			if allowed[i] == fmt.Sprint(v) {
+				return
+			}
+		}
+		fail(fmt.Errorf("expected one of %s, got %v", strings.Join(allowed, ", "), v))
+	}
+}
+
+// k0sctlK0sConfigIsInteger whether a json number is an integer.
+func k0sctlK0sConfigIsInteger(v interface{}) bool {
+	f, ok := v.(float64)
+	return ok && f == math.Trunc(f)
+}
+
+// k0sctlK0sConfigCheckFormat check the formats of the strings which k0s declares in its schema, other formats are
+// accepted as is.
+func k0sctlK0sConfigCheckFormat(format, v string) error {
+	switch format {
+	case "cidr":
+		if _, _, err := net.ParseCIDR(v); err != nil {
+			return fmt.Errorf("%q is not a CIDR", v)
+		}
+	case "ip", "ipv4", "ipv6":
+		if ip := net.ParseIP(v); ip == nil || (format == "ipv4" && ip.To4() == nil) || (format == "ipv6" && ip.To4() != nil) {
+			return fmt.Errorf("%q is not an %s address", v, format)
+		}
+	}
+	return nil
+}
+
+// k0sctlK0sConfigPath the yaml path of a field, e.g. spec.network.podCIDR or spec.workerProfiles[0].name.
+func k0sctlK0sConfigPath(yp []string) string {
+	return strings.ReplaceAll(strings.Join(yp, "."), ".[", "[")
+}
+
+func k0sctlK0sConfigFieldNames(fields map[string]*k0sctlK0sConfigSchema) string {
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// k0sctlK0sConfigDiagnostics validate the k0s config against the ClusterConfig schema of the k0s version. A config
+// which is not known yet or can't be converted is left to the cluster conversion.
+func k0sctlK0sConfigDiagnostics(config types.Dynamic, s *k0sctlK0sConfigSchema, version string) diag.Diagnostics {
+	d := diag.Diagnostics{}
+
+	v, err := k0sctlK0sConfigNormalized(config)
+	if err != nil {
+		return d
+	}
+
+	cp := path.Root("spec").AtName("k0s").AtName("config")
+	for _, kce := range s.Validate(v) {
+		if kce.Unknown {
+			d.AddAttributeWarning(cp, fmt.Sprintf("Unknown k0s config field %s", kce.Path), fmt.Sprintf("%s\n\nThe field is not in the ClusterConfig of k0s %s, which ignores it.", kce.Error(), version))
+		} else {
+			d.AddAttributeError(cp, fmt.Sprintf("Invalid k0s config at %s", kce.Path), fmt.Sprintf("%s\n\nThe field does not match the ClusterConfig of k0s %s.", kce.Error(), version))
+		}
+	}
+
+	return d
+}
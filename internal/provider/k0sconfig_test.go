@@ -0,0 +1,376 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+
+	k0sversion "github.com/k0sproject/version"
+)
+
+func TestK0sctlK0sConfigMapping(t *testing.T) {
+	ctx := context.Background()
+
+	yamlConfig := types.DynamicValue(types.StringValue(`
+spec:
+  network:
+    provider: calico
+  api:
+    port: 6443
+`))
+
+	// the same config, with other formatting
+	yamlConfigReformatted := types.DynamicValue(types.StringValue("spec: {api: {port: 6443}, network: {provider: calico}}\n"))
+
+	objectConfig := types.DynamicValue(types.ObjectValueMust(
+		map[string]attr.Type{
+			"spec": types.ObjectType{AttrTypes: map[string]attr.Type{
+				"network": types.ObjectType{AttrTypes: map[string]attr.Type{"provider": types.StringType}},
+				"api":     types.ObjectType{AttrTypes: map[string]attr.Type{"port": types.NumberType}},
+			}},
+		},
+		map[string]attr.Value{
+			"spec": types.ObjectValueMust(
+				map[string]attr.Type{
+					"network": types.ObjectType{AttrTypes: map[string]attr.Type{"provider": types.StringType}},
+					"api":     types.ObjectType{AttrTypes: map[string]attr.Type{"port": types.NumberType}},
+				},
+				map[string]attr.Value{
+					"network": types.ObjectValueMust(map[string]attr.Type{"provider": types.StringType}, map[string]attr.Value{"provider": types.StringValue("calico")}),
+					"api":     types.ObjectValueMust(map[string]attr.Type{"port": types.NumberType}, map[string]attr.Value{"port": types.NumberValue(big.NewFloat(6443))}),
+				},
+			),
+		},
+	))
+
+	for name, config := range map[string]types.Dynamic{"yaml": yamlConfig, "object": objectConfig} {
+		dm, err := k0sctlK0sConfigMapping(config)
+		if err != nil {
+			t.Fatalf("%s config could not be converted: %s", name, err)
+		}
+		if p := dm.DigString("spec", "network", "provider"); p != "calico" {
+			t.Errorf("%s config has network provider %q, expected calico", name, p)
+		}
+		if p := dm.DigMapping("spec", "api")["port"]; p != 6443 {
+			t.Errorf("%s config has api port %#v, expected 6443", name, p)
+		}
+	}
+
+	if _, err := k0sctlK0sConfigMapping(types.DynamicValue(types.StringValue("spec: [network"))); err == nil {
+		t.Error("invalid yaml config was converted")
+	}
+
+	if dm, err := k0sctlK0sConfigMapping(types.DynamicNull()); err != nil || len(dm) > 0 {
+		t.Errorf("null config was converted to %v, %v", dm, err)
+	}
+
+	// the same numbers are equal, whether they are integers or floats
+	yamlConfigFloat := types.DynamicValue(types.StringValue("spec: {api: {port: 6443.0}, network: {provider: calico}}\n"))
+
+	for _, pv := range []types.Dynamic{yamlConfigReformatted, objectConfig, yamlConfigFloat} {
+		req := planmodifier.DynamicRequest{StateValue: yamlConfig, ConfigValue: pv, PlanValue: pv}
+		resp := &planmodifier.DynamicResponse{PlanValue: pv}
+		k0sctlK0sConfigSemanticEquality().PlanModifyDynamic(ctx, req, resp)
+		if !resp.PlanValue.Equal(yamlConfig) {
+			t.Errorf("equivalent config %s was planned as a change", pv)
+		}
+	}
+
+	changed := types.DynamicValue(types.StringValue("spec: {network: {provider: kuberouter}}"))
+	req := planmodifier.DynamicRequest{StateValue: yamlConfig, ConfigValue: changed, PlanValue: changed}
+	resp := &planmodifier.DynamicResponse{PlanValue: changed}
+	k0sctlK0sConfigSemanticEquality().PlanModifyDynamic(ctx, req, resp)
+	if !resp.PlanValue.Equal(changed) {
+		t.Error("changed config was not planned")
+	}
+
+	// the config is computed, but only from the configuration
+	req = planmodifier.DynamicRequest{StateValue: yamlConfig, ConfigValue: types.DynamicNull(), PlanValue: types.DynamicUnknown()}
+	resp = &planmodifier.DynamicResponse{PlanValue: types.DynamicUnknown()}
+	k0sctlK0sConfigSemanticEquality().PlanModifyDynamic(ctx, req, resp)
+	if !resp.PlanValue.IsNull() {
+		t.Errorf("removed config was planned as %s", resp.PlanValue)
+	}
+}
+
+func TestK0sctlUpgradeStateV0(t *testing.T) {
+	req := resource.UpgradeStateRequest{
+		RawState: &tfprotov6.RawState{
+			JSON: []byte(`{"id":"test","spec":{"k0s":{"version":"1.30.1+k0s.0","config":"spec:\n  network:\n    provider: calico\n"},"host":[{"role":"controller","ssh":[{"port":22}]}]}}`),
+		},
+	}
+	resp := &resource.UpgradeStateResponse{}
+
+	k0sctlUpgradeStateV0(context.Background(), req, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("state upgrade failed: %v", resp.Diagnostics)
+	}
+
+	var rs struct {
+		Spec struct {
+			K0s struct {
+				Config struct {
+					Value string `json:"value"`
+					Type  string `json:"type"`
+				} `json:"config"`
+			} `json:"k0s"`
+			Host []struct {
+				SSH []struct {
+					Port json.Number `json:"port"`
+				} `json:"ssh"`
+			} `json:"host"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(resp.DynamicValue.JSON, &rs); err != nil {
+		t.Fatalf("upgraded state is not json: %s", err)
+	}
+
+	if rs.Spec.K0s.Config.Type != "string" || rs.Spec.K0s.Config.Value != "spec:\n  network:\n    provider: calico\n" {
+		t.Errorf("k0s config was not upgraded to a dynamic string: %+v", rs.Spec.K0s.Config)
+	}
+	if rs.Spec.Host[0].SSH[0].Port != "22" {
+		t.Errorf("other state values changed in the upgrade: port %s", rs.Spec.Host[0].SSH[0].Port)
+	}
+
+	// the framework must be able to read the upgraded state with the current schema
+	if _, err := resp.DynamicValue.Unmarshal(k0sctl_v1beta1_schema().Type().TerraformType(context.Background())); err != nil {
+		t.Errorf("upgraded state does not match the schema: %s", err)
+	}
+}
+
+// testK0sctlK0sConfigCRD a part of the ClusterConfig CRD, as k0s publishes it.
+const testK0sctlK0sConfigCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: clusterconfigs.k0s.k0sproject.io
+spec:
+  group: k0s.k0sproject.io
+  names:
+    kind: ClusterConfig
+  versions:
+  - name: v1beta1
+    schema:
+      openAPIV3Schema:
+        description: ClusterConfig cluster manifest
+        type: object
+        properties:
+          apiVersion:
+            type: string
+          kind:
+            type: string
+          metadata:
+            type: object
+          spec:
+            type: object
+            properties:
+              api:
+                type: object
+                properties:
+                  externalAddress:
+                    type: string
+                  sans:
+                    type: array
+                    items:
+                      type: string
+                  port:
+                    type: integer
+                    minimum: 1
+                    maximum: 65535
+                  extraArgs:
+                    type: object
+                    additionalProperties:
+                      type: string
+              network:
+                type: object
+                properties:
+                  provider:
+                    type: string
+                    enum:
+                    - kuberouter
+                    - calico
+                    - custom
+                  podCIDR:
+                    type: string
+                    format: cidr
+                  serviceCIDR:
+                    type: string
+                  calico:
+                    type: object
+                    properties:
+                      mode:
+                        type: string
+                      envVars:
+                        type: object
+                        additionalProperties:
+                          type: string
+              workerProfiles:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    name:
+                      type: string
+                    values:
+                      type: object
+                      x-kubernetes-preserve-unknown-fields: true
+              telemetry:
+                type: object
+                properties:
+                  enabled:
+                    type: boolean
+              konnectivity:
+                type: object
+                properties:
+                  agentPort:
+                    x-kubernetes-int-or-string: true
+                    anyOf:
+                    - type: integer
+                    - type: string
+`
+
+func TestK0sctlK0sConfigSchemaDownload(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/k0s/v1.30.2+k0s.0/"+k0sctlK0sConfigSchemaPath, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testK0sctlK0sConfigCRD))
+	})
+	mux.HandleFunc("/k0s/v1.30.3+k0s.0/"+k0sctlK0sConfigSchemaPath, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html>maintenance</html>"))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx := context.Background()
+
+	s, err := k0sctlK0sConfigSchemaDownload(ctx, srv.URL+"/k0s/", k0sversion.MustParse("v1.30.2+k0s.0"))
+	if err != nil {
+		t.Fatalf("could not download the schema: %s", err)
+	}
+	if s.Properties["spec"].Properties["network"].Properties["podCIDR"].Format != "cidr" {
+		t.Errorf("the schema was not read from the CRD: %+v", s)
+	}
+
+	if _, err := k0sctlK0sConfigSchemaDownload(ctx, srv.URL+"/k0s", k0sversion.MustParse("v1.30.3+k0s.0")); err == nil {
+		t.Error("an invalid CRD was accepted")
+	}
+
+	if _, err := k0sctlK0sConfigSchemaDownload(ctx, srv.URL+"/k0s", k0sversion.MustParse("v1.29.0+k0s.0")); err == nil {
+		t.Error("a missing CRD was accepted")
+	}
+}
+
+func TestK0sctlK0sConfigSchemaValidate(t *testing.T) {
+	var crd k0sctlK0sConfigCRD
+	if err := yaml.Unmarshal([]byte(testK0sctlK0sConfigCRD), &crd); err != nil {
+		t.Fatalf("the CRD could not be read: %s", err)
+	}
+	s := crd.Spec.Versions[0].Schema.OpenAPIV3Schema
+
+	config := func(y string) interface{} {
+		v, err := k0sctlK0sConfigNormalized(types.DynamicValue(types.StringValue(y)))
+		if err != nil {
+			t.Fatalf("config could not be converted: %s", err)
+		}
+		return v
+	}
+
+	valid := config(`
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+metadata:
+  name: k0s
+spec:
+  api:
+    externalAddress: k8s.example.org
+    sans: [k8s.example.org, 10.0.0.10]
+    port: 6443
+    extraArgs:
+      audit-log-maxage: "30"
+  network:
+    provider: calico
+    podCIDR: 10.244.0.0/16
+    serviceCIDR: 10.96.0.0/12
+    calico:
+      mode: vxlan
+      envVars:
+        CALICO_IPV4POOL_NAT_OUTGOING: "true"
+  workerProfiles:
+  - name: custom
+    values:
+      maxPods: 200
+  telemetry:
+    enabled: false
+  konnectivity:
+    agentPort: 8132
+`)
+	if errs := s.Validate(valid); len(errs) > 0 {
+		t.Errorf("valid config has errors: %v", errs)
+	}
+	if errs := s.Validate(nil); len(errs) > 0 {
+		t.Errorf("no config has errors: %v", errs)
+	}
+
+	invalid := config(`
+spec:
+  api:
+    port: 70000
+    extraArgs:
+      audit-log-maxage: 30
+  network:
+    provider: flannel
+    podCidr: 10.244.0.0/16
+    podCIDR: 10.244.0.0
+  workerProfiles:
+  - name: custom
+    value: {}
+  telemetry: true
+  konnectivity:
+    agentPort: 81.32
+`)
+	// unknown fields are only warned about
+	expected := []k0sctlK0sConfigError{
+		{Path: "spec.api.extraArgs.audit-log-maxage"},
+		{Path: "spec.api.port"},
+		{Path: "spec.konnectivity.agentPort"},
+		{Path: "spec.network.podCIDR"},
+		{Path: "spec.network.podCidr", Unknown: true},
+		{Path: "spec.network.provider"},
+		{Path: "spec.telemetry"},
+		{Path: "spec.workerProfiles[0].value", Unknown: true},
+	}
+
+	errs := s.Validate(invalid)
+	if len(errs) != len(expected) {
+		t.Fatalf("expected errors at %v, got %v", expected, errs)
+	}
+	for i, e := range errs {
+		if e.Path != expected[i].Path || e.Unknown != expected[i].Unknown {
+			t.Errorf("expected an error at %s (unknown field: %t), got %s (unknown field: %t)", expected[i].Path, expected[i].Unknown, e, e.Unknown)
+		}
+	}
+
+	// the object config is validated the same
+	objectConfig := types.DynamicValue(types.ObjectValueMust(
+		map[string]attr.Type{"spec": types.ObjectType{AttrTypes: map[string]attr.Type{"telemetry": types.BoolType}}},
+		map[string]attr.Value{"spec": types.ObjectValueMust(map[string]attr.Type{"telemetry": types.BoolType}, map[string]attr.Value{"telemetry": types.BoolValue(true)})},
+	))
+	if d := k0sctlK0sConfigDiagnostics(objectConfig, s, "v1.30.2+k0s.0"); d.ErrorsCount() != 1 {
+		t.Errorf("an invalid object config was not reported as a single error: %v", d)
+	}
+
+	if d := k0sctlK0sConfigDiagnostics(types.DynamicValue(types.StringValue("spec: {network: {podCidr: 10.244.0.0/16}}")), s, "v1.30.2+k0s.0"); d.HasError() || d.WarningsCount() != 1 {
+		t.Errorf("an unknown field was not reported as a single warning: %v", d)
+	}
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	k0sversion "github.com/k0sproject/version"
+)
+
+// k0sctlK0sVersionLookupTimeout how long the lookup of the version of a release channel may take.
+const k0sctlK0sVersionLookupTimeout = 30 * time.Second
+
+// k0sctlK0sChannelVersion look up the latest k0s version of a release channel (stable or latest), from the
+// stable.txt or latest.txt file under the endpoint.
+//
+// k0sctl looks the version up from docs.k0sproject.io only, so the lookup is done here to allow for a mirror.
+func k0sctlK0sChannelVersion(ctx context.Context, endpoint, channel string) (*k0sversion.Version, error) {
+	if channel == "" {
+		channel = "stable"
+	}
+
+	u := strings.TrimSuffix(endpoint, "/") + "/" + channel + ".txt"
+
+	ctx, cancel := context.WithTimeout(ctx, k0sctlK0sVersionLookupTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with %s", u, res.Status)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(res.Body, 1024))
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := k0sversion.NewVersion(strings.TrimSpace(string(b)))
+	if err != nil {
+		return nil, fmt.Errorf("%s did not respond with a k0s version: %w", u, err)
+	}
+
+	return v, nil
+}
+
+// k0sctlK0sUpgradeDiagnostics check the change of the k0s version of a cluster, k0s (like kubernetes) can only be
+// upgraded one minor version at a time, and downgrades are refused by k0sctl unless its check is disabled.
+//
+// An upgrade is reported as a warning listing the order in which k0sctl upgrades the hosts, controllers are upgraded
+// one at a time before the workers.
+func k0sctlK0sUpgradeDiagnostics(prior, planned *k0sversion.Version, disableDowngradeCheck bool, controllers, workers []string) diag.Diagnostics {
+	d := diag.Diagnostics{}
+	vp := path.Root("spec").AtName("k0s").AtName("version")
+
+	if prior == nil || planned == nil || planned.Equal(prior) {
+		return d
+	}
+
+	if planned.LessThan(prior) {
+		if !disableDowngradeCheck {
+			d.AddAttributeError(vp, "K0s downgrade", fmt.Sprintf("The k0s version would be downgraded from %s to %s, which k0s does not support. Set disable_downgrade_check to downgrade anyway.", prior, planned))
+		}
+		return d
+	}
+
+	ps, ns := prior.Segments(), planned.Segments()
+	if len(ps) < 2 || len(ns) < 2 {
+		return d
+	}
+
+	if ns[0] != ps[0] || ns[1] > ps[1]+1 {
+		d.AddAttributeError(vp, "K0s upgrade skips a Kubernetes minor version", fmt.Sprintf("The k0s version would be upgraded from %s to %s, which skips a Kubernetes minor version. Kubernetes can only be upgraded one minor version at a time, upgrade to %d.%d first.", prior, planned, ps[0], ps[1]+1))
+		return d
+	}
+
+	order := []string{}
+	if len(controllers) > 0 {
+		order = append(order, fmt.Sprintf("the controllers one at a time (%s)", strings.Join(controllers, ", ")))
+	}
+	if len(workers) > 0 {
+		order = append(order, fmt.Sprintf("the workers, which are drained unless no_drain is set (%s)", strings.Join(workers, ", ")))
+	}
+
+	d.AddAttributeWarning(vp, "K0s upgrade", fmt.Sprintf("The k0s version will be upgraded from %s to %s, as a rolling upgrade of %s.", prior, planned, strings.Join(order, ", then ")))
+
+	return d
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	k0sversion "github.com/k0sproject/version"
+)
+
+func TestK0sctlK0sChannelVersion(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/k0s/stable.txt", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("v1.30.2+k0s.0\n"))
+	})
+	mux.HandleFunc("/k0s/latest.txt", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("v1.31.0-rc.1+k0s.0\n"))
+	})
+	mux.HandleFunc("/broken/stable.txt", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html>maintenance</html>"))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx := context.Background()
+
+	for channel, want := range map[string]string{
+		"":       "v1.30.2+k0s.0",
+		"stable": "v1.30.2+k0s.0",
+		"latest": "v1.31.0-rc.1+k0s.0",
+	} {
+		if v, err := k0sctlK0sChannelVersion(ctx, srv.URL+"/k0s/", channel); err != nil {
+			t.Errorf("could not look up the %q channel version: %s", channel, err)
+		} else if v.String() != want {
+			t.Errorf("%q channel version %s, expected %s", channel, v, want)
+		}
+	}
+
+	if _, err := k0sctlK0sChannelVersion(ctx, srv.URL+"/broken", "stable"); err == nil {
+		t.Error("an invalid version was accepted")
+	}
+
+	if _, err := k0sctlK0sChannelVersion(ctx, srv.URL+"/missing", "stable"); err == nil {
+		t.Error("a missing version file was accepted")
+	}
+}
+
+func TestK0sctlK0sUpgradeDiagnostics(t *testing.T) {
+	v := func(s string) *k0sversion.Version {
+		return k0sversion.MustParse(s)
+	}
+
+	controllers := []string{"10.0.0.1 (controller)", "10.0.0.2 (controller+worker)"}
+	workers := []string{"10.0.0.11", "10.0.0.12"}
+
+	for _, c := range []struct {
+		name                  string
+		prior, planned        string
+		disableDowngradeCheck bool
+		errs, warns           int
+	}{
+		{name: "unchanged", prior: "v1.30.2+k0s.0", planned: "v1.30.2+k0s.0"},
+		{name: "patch upgrade", prior: "v1.30.1+k0s.0", planned: "v1.30.2+k0s.0", warns: 1},
+		{name: "minor upgrade", prior: "v1.30.2+k0s.0", planned: "v1.31.1+k0s.0", warns: 1},
+		{name: "minor skip", prior: "v1.29.6+k0s.0", planned: "v1.31.1+k0s.0", errs: 1},
+		{name: "major upgrade", prior: "v1.31.1+k0s.0", planned: "v2.0.0+k0s.0", errs: 1},
+		{name: "downgrade", prior: "v1.30.2+k0s.0", planned: "v1.30.1+k0s.0", errs: 1},
+		{name: "allowed downgrade", prior: "v1.30.2+k0s.0", planned: "v1.29.6+k0s.0", disableDowngradeCheck: true},
+	} {
+		d := k0sctlK0sUpgradeDiagnostics(v(c.prior), v(c.planned), c.disableDowngradeCheck, controllers, workers)
+		if d.ErrorsCount() != c.errs || d.WarningsCount() != c.warns {
+			t.Errorf("%s: expected %d errors and %d warnings, got %v", c.name, c.errs, c.warns, d)
+		}
+	}
+
+	d := k0sctlK0sUpgradeDiagnostics(v("v1.30.1+k0s.0"), v("v1.30.2+k0s.0"), false, controllers, workers)
+	if got := d[0].Detail(); !strings.Contains(got, "controllers one at a time (10.0.0.1 (controller), 10.0.0.2 (controller+worker)), then the workers") || !strings.Contains(got, "(10.0.0.11, 10.0.0.12)") {
+		t.Errorf("the upgrade warning does not list the upgrade order: %s", got)
+	}
+}
@@ -0,0 +1,303 @@
+package provider
+
+import (
+	"context"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// k0sctlKubeconfig the kubeconfig file schema, as in k8s.io/client-go/tools/clientcmd/api/v1.
+//
+// Fields which are not part of the schema are ignored rather than rejected, so that kubeconfigs produced by
+// newer k0s versions can still be read.
+type k0sctlKubeconfig struct {
+	APIVersion     string                           `yaml:"apiVersion,omitempty"`
+	Kind           string                           `yaml:"kind,omitempty"`
+	Preferences    k0sctlKubeconfigPreferences      `yaml:"preferences"`
+	Clusters       []k0sctlKubeconfigNamedCluster   `yaml:"clusters"`
+	Users          []k0sctlKubeconfigNamedUser      `yaml:"users"`
+	Contexts       []k0sctlKubeconfigNamedContext   `yaml:"contexts"`
+	CurrentContext string                           `yaml:"current-context"`
+	Extensions     []k0sctlKubeconfigNamedExtension `yaml:"extensions,omitempty"`
+}
+
+type k0sctlKubeconfigPreferences struct {
+	Colors     bool                             `yaml:"colors,omitempty"`
+	Extensions []k0sctlKubeconfigNamedExtension `yaml:"extensions,omitempty"`
+}
+
+type k0sctlKubeconfigNamedCluster struct {
+	Name    string                  `yaml:"name"`
+	Cluster k0sctlKubeconfigCluster `yaml:"cluster"`
+}
+
+type k0sctlKubeconfigCluster struct {
+	Server                   string                           `yaml:"server"`
+	TLSServerName            string                           `yaml:"tls-server-name,omitempty"`
+	InsecureSkipTLSVerify    bool                             `yaml:"insecure-skip-tls-verify,omitempty"`
+	CertificateAuthority     string                           `yaml:"certificate-authority,omitempty"`
+	CertificateAuthorityData string                           `yaml:"certificate-authority-data,omitempty"`
+	ProxyURL                 string                           `yaml:"proxy-url,omitempty"`
+	DisableCompression       bool                             `yaml:"disable-compression,omitempty"`
+	Extensions               []k0sctlKubeconfigNamedExtension `yaml:"extensions,omitempty"`
+}
+
+type k0sctlKubeconfigNamedUser struct {
+	Name string               `yaml:"name"`
+	User k0sctlKubeconfigUser `yaml:"user"`
+}
+
+type k0sctlKubeconfigUser struct {
+	ClientCertificate     string                           `yaml:"client-certificate,omitempty"`
+	ClientCertificateData string                           `yaml:"client-certificate-data,omitempty"`
+	ClientKey             string                           `yaml:"client-key,omitempty"`
+	ClientKeyData         string                           `yaml:"client-key-data,omitempty"`
+	Token                 string                           `yaml:"token,omitempty"`
+	TokenFile             string                           `yaml:"tokenFile,omitempty"`
+	Impersonate           string                           `yaml:"as,omitempty"`
+	ImpersonateUID        string                           `yaml:"as-uid,omitempty"`
+	ImpersonateGroups     []string                         `yaml:"as-groups,omitempty"`
+	ImpersonateUserExtra  map[string][]string              `yaml:"as-user-extra,omitempty"`
+	Username              string                           `yaml:"username,omitempty"`
+	Password              string                           `yaml:"password,omitempty"`
+	AuthProvider          *k0sctlKubeconfigAuthProvider    `yaml:"auth-provider,omitempty"`
+	Exec                  *k0sctlKubeconfigExec            `yaml:"exec,omitempty"`
+	Extensions            []k0sctlKubeconfigNamedExtension `yaml:"extensions,omitempty"`
+}
+
+type k0sctlKubeconfigAuthProvider struct {
+	Name   string            `yaml:"name"`
+	Config map[string]string `yaml:"config"`
+}
+
+type k0sctlKubeconfigExec struct {
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args"`
+	Env     []struct {
+		Name  string `yaml:"name"`
+		Value string `yaml:"value"`
+	} `yaml:"env"`
+	APIVersion         string `yaml:"apiVersion,omitempty"`
+	InstallHint        string `yaml:"installHint,omitempty"`
+	ProvideClusterInfo bool   `yaml:"provideClusterInfo"`
+	InteractiveMode    string `yaml:"interactiveMode,omitempty"`
+}
+
+type k0sctlKubeconfigNamedContext struct {
+	Name    string                  `yaml:"name"`
+	Context k0sctlKubeconfigContext `yaml:"context"`
+}
+
+type k0sctlKubeconfigContext struct {
+	Cluster    string                           `yaml:"cluster"`
+	AuthInfo   string                           `yaml:"user"`
+	Namespace  string                           `yaml:"namespace,omitempty"`
+	Extensions []k0sctlKubeconfigNamedExtension `yaml:"extensions,omitempty"`
+}
+
+type k0sctlKubeconfigNamedExtension struct {
+	Name      string      `yaml:"name"`
+	Extension interface{} `yaml:"extension"`
+}
+
+// k0sctlKubeconfigParse interpret kube config file bytes.
+func k0sctlKubeconfigParse(k8bytes []byte) (k0sctlKubeconfig, diag.Diagnostics) {
+	d := diag.Diagnostics{}
+	var kc k0sctlKubeconfig
+
+	if err := yaml.Unmarshal(k8bytes, &kc); err != nil {
+		d.AddError("Error interpreting k8s context from k0sctl response", err.Error())
+	}
+
+	return kc, d
+}
+
+// k0sctlKubeconfigCredentials the connection values of the current context of a kubeconfig.
+type k0sctlKubeconfigCredentials struct {
+	Host       types.String
+	CaCert     types.String
+	ClientCert types.String
+	PrivateKey types.String
+}
+
+// Current the connection values of the current context.
+//
+// Values which are not found in the kube config are left null.
+func (kc k0sctlKubeconfig) Current() k0sctlKubeconfigCredentials {
+	kcc := k0sctlKubeconfigCredentials{
+		Host:       types.StringNull(),
+		CaCert:     types.StringNull(),
+		ClientCert: types.StringNull(),
+		PrivateKey: types.StringNull(),
+	}
+
+	var clusterName, userName string
+
+	for _, context := range kc.Contexts {
+		if context.Name == kc.CurrentContext {
+			clusterName = context.Context.Cluster
+			userName = context.Context.AuthInfo
+			break
+		}
+	}
+
+	for _, cluster := range kc.Clusters {
+		if cluster.Name == clusterName {
+			kcc.Host = types.StringValue(cluster.Cluster.Server)
+			kcc.CaCert = types.StringValue(helperStringBase64Decode(cluster.Cluster.CertificateAuthorityData))
+			break
+		}
+	}
+
+	for _, user := range kc.Users {
+		if user.Name == userName {
+			kcc.PrivateKey = types.StringValue(helperStringBase64Decode(user.User.ClientKeyData))
+			kcc.ClientCert = types.StringValue(helperStringBase64Decode(user.User.ClientCertificateData))
+			break
+		}
+	}
+
+	return kcc
+}
+
+// ObjectValue the kubeconfig as a terraform value of k0sctlKubeconfigType.
+func (kc k0sctlKubeconfig) ObjectValue(ctx context.Context) (types.Object, diag.Diagnostics) {
+	m := k0sctlSchemaModelKubeconfig{
+		CurrentContext: types.StringValue(kc.CurrentContext),
+	}
+
+	cs := make([]k0sctlSchemaModelKubeconfigCluster, len(kc.Clusters))
+	for i, c := range kc.Clusters {
+		cs[i] = k0sctlSchemaModelKubeconfigCluster{
+			Name:                  types.StringValue(c.Name),
+			Server:                types.StringValue(c.Cluster.Server),
+			CaCert:                types.StringValue(helperStringBase64Decode(c.Cluster.CertificateAuthorityData)),
+			TLSServerName:         types.StringValue(c.Cluster.TLSServerName),
+			InsecureSkipTLSVerify: types.BoolValue(c.Cluster.InsecureSkipTLSVerify),
+			ProxyURL:              types.StringValue(c.Cluster.ProxyURL),
+		}
+	}
+
+	ctxs := make([]k0sctlSchemaModelKubeconfigContext, len(kc.Contexts))
+	for i, c := range kc.Contexts {
+		ctxs[i] = k0sctlSchemaModelKubeconfigContext{
+			Name:      types.StringValue(c.Name),
+			Cluster:   types.StringValue(c.Context.Cluster),
+			User:      types.StringValue(c.Context.AuthInfo),
+			Namespace: types.StringValue(c.Context.Namespace),
+		}
+	}
+
+	us := make([]k0sctlSchemaModelKubeconfigUser, len(kc.Users))
+	for i, u := range kc.Users {
+		us[i] = k0sctlSchemaModelKubeconfigUser{
+			Name:        types.StringValue(u.Name),
+			ClientCert:  types.StringValue(helperStringBase64Decode(u.User.ClientCertificateData)),
+			PrivateKey:  types.StringValue(helperStringBase64Decode(u.User.ClientKeyData)),
+			Token:       types.StringValue(u.User.Token),
+			Username:    types.StringValue(u.User.Username),
+			Password:    types.StringValue(u.User.Password),
+			ExecCommand: types.StringNull(),
+		}
+		if u.User.Exec != nil {
+			us[i].ExecCommand = types.StringValue(u.User.Exec.Command)
+		}
+	}
+
+	d := diag.Diagnostics{}
+	var ds diag.Diagnostics
+
+	m.Clusters, ds = types.ListValueFrom(ctx, k0sctlKubeconfigClusterType, cs)
+	d.Append(ds...)
+	m.Contexts, ds = types.ListValueFrom(ctx, k0sctlKubeconfigContextType, ctxs)
+	d.Append(ds...)
+	m.Users, ds = types.ListValueFrom(ctx, k0sctlKubeconfigUserType, us)
+	d.Append(ds...)
+
+	if d.HasError() {
+		return types.ObjectNull(k0sctlKubeconfigType.AttrTypes), d
+	}
+
+	ov, ds := types.ObjectValueFrom(ctx, k0sctlKubeconfigType.AttrTypes, m)
+	d.Append(ds...)
+
+	return ov, d
+}
+
+var k0sctlKubeconfigClusterType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"name":                     types.StringType,
+		"server":                   types.StringType,
+		"ca_cert":                  types.StringType,
+		"tls_server_name":          types.StringType,
+		"insecure_skip_tls_verify": types.BoolType,
+		"proxy_url":                types.StringType,
+	},
+}
+
+var k0sctlKubeconfigContextType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"name":      types.StringType,
+		"cluster":   types.StringType,
+		"user":      types.StringType,
+		"namespace": types.StringType,
+	},
+}
+
+var k0sctlKubeconfigUserType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"name":         types.StringType,
+		"client_cert":  types.StringType,
+		"private_key":  types.StringType,
+		"token":        types.StringType,
+		"username":     types.StringType,
+		"password":     types.StringType,
+		"exec_command": types.StringType,
+	},
+}
+
+var k0sctlKubeconfigType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"current_context": types.StringType,
+		"clusters":        types.ListType{ElemType: k0sctlKubeconfigClusterType},
+		"contexts":        types.ListType{ElemType: k0sctlKubeconfigContextType},
+		"users":           types.ListType{ElemType: k0sctlKubeconfigUserType},
+	},
+}
+
+type k0sctlSchemaModelKubeconfig struct {
+	CurrentContext types.String `tfsdk:"current_context"`
+	Clusters       types.List   `tfsdk:"clusters"`
+	Contexts       types.List   `tfsdk:"contexts"`
+	Users          types.List   `tfsdk:"users"`
+}
+
+type k0sctlSchemaModelKubeconfigCluster struct {
+	Name                  types.String `tfsdk:"name"`
+	Server                types.String `tfsdk:"server"`
+	CaCert                types.String `tfsdk:"ca_cert"`
+	TLSServerName         types.String `tfsdk:"tls_server_name"`
+	InsecureSkipTLSVerify types.Bool   `tfsdk:"insecure_skip_tls_verify"`
+	ProxyURL              types.String `tfsdk:"proxy_url"`
+}
+
+type k0sctlSchemaModelKubeconfigContext struct {
+	Name      types.String `tfsdk:"name"`
+	Cluster   types.String `tfsdk:"cluster"`
+	User      types.String `tfsdk:"user"`
+	Namespace types.String `tfsdk:"namespace"`
+}
+
+type k0sctlSchemaModelKubeconfigUser struct {
+	Name        types.String `tfsdk:"name"`
+	ClientCert  types.String `tfsdk:"client_cert"`
+	PrivateKey  types.String `tfsdk:"private_key"`
+	Token       types.String `tfsdk:"token"`
+	Username    types.String `tfsdk:"username"`
+	Password    types.String `tfsdk:"password"`
+	ExecCommand types.String `tfsdk:"exec_command"`
+}
@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	ephemeral_schema "github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	resource_schema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func k0sctl_kubeconfig_data_source_schema() schema.Schema {
+	return schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Admin kubeconfig of an existing k0s cluster, fetched from the controllers each time the data source is read. The cluster is not altered.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Kubeconfig identifier, the name of the cluster",
+			},
+
+			"api_address": schema.StringAttribute{
+				MarkdownDescription: "Address to use for the Kubernetes API in the kubeconfig, instead of the address of the first controller (e.g. a load balancer)",
+				Optional:            true,
+			},
+
+			"kube_yaml": schema.StringAttribute{
+				MarkdownDescription: "K8 Kubernetes API client configuration yaml file",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"kube_host": schema.StringAttribute{
+				MarkdownDescription: "K8 Kubernetes API host endpoint",
+				Computed:            true,
+			},
+			"private_key": schema.StringAttribute{
+				MarkdownDescription: "K8 Private key for the user",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"client_cert": schema.StringAttribute{
+				MarkdownDescription: "K8 Client certificate for the user",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"ca_cert": schema.StringAttribute{
+				MarkdownDescription: "K8 Server CA certificate",
+				Computed:            true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"metadata": k0sctlDataSourceBlock(k0sctlKubeconfigMetadataBlock()),
+			"spec":     k0sctlDataSourceBlock(k0sctlHostsSpecBlock()),
+		},
+	}
+}
+
+func k0sctl_kubeconfig_ephemeral_resource_schema() ephemeral_schema.Schema {
+	return ephemeral_schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Admin kubeconfig of an existing k0s cluster, fetched from the controllers on each terraform run and never stored in the plan or state. The cluster is not altered.",
+
+		Attributes: map[string]ephemeral_schema.Attribute{
+			"id": ephemeral_schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Kubeconfig identifier, the name of the cluster",
+			},
+
+			"api_address": ephemeral_schema.StringAttribute{
+				MarkdownDescription: "Address to use for the Kubernetes API in the kubeconfig, instead of the address of the first controller (e.g. a load balancer)",
+				Optional:            true,
+			},
+
+			"kube_yaml": ephemeral_schema.StringAttribute{
+				MarkdownDescription: "K8 Kubernetes API client configuration yaml file",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"kube_host": ephemeral_schema.StringAttribute{
+				MarkdownDescription: "K8 Kubernetes API host endpoint",
+				Computed:            true,
+			},
+			"private_key": ephemeral_schema.StringAttribute{
+				MarkdownDescription: "K8 Private key for the user",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"client_cert": ephemeral_schema.StringAttribute{
+				MarkdownDescription: "K8 Client certificate for the user",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"ca_cert": ephemeral_schema.StringAttribute{
+				MarkdownDescription: "K8 Server CA certificate",
+				Computed:            true,
+			},
+		},
+
+		Blocks: map[string]ephemeral_schema.Block{
+			"metadata": k0sctlEphemeralBlock(k0sctlKubeconfigMetadataBlock()),
+			"spec":     k0sctlEphemeralBlock(k0sctlHostsSpecBlock()),
+		},
+	}
+}
+
+// k0sctl_kubeconfig_data_source_shared_schema the resource schema of the blocks which the data source and the ephemeral
+// resource share with the resources, which holds their defaults.
+func k0sctl_kubeconfig_data_source_shared_schema() resource_schema.Schema {
+	return resource_schema.Schema{
+		Blocks: map[string]resource_schema.Block{
+			"metadata": k0sctlKubeconfigMetadataBlock(),
+			"spec":     k0sctlHostsSpecBlock(),
+		},
+	}
+}
+
+// k0sctlKubeconfigMetadataBlock the metadata block, required as the cluster name identifies the kubeconfig.
+func k0sctlKubeconfigMetadataBlock() resource_schema.SingleNestedBlock {
+	b := k0sctlMetadataBlock()
+	b.Validators = []validator.Object{
+		objectvalidator.IsRequired(),
+	}
+	return b
+}
+
+type k0sctlKubeconfigSchemaModel struct {
+	Id types.String `tfsdk:"id"`
+
+	APIAddress types.String `tfsdk:"api_address"`
+
+	KubeYaml   types.String `tfsdk:"kube_yaml"`
+	KubeHost   types.String `tfsdk:"kube_host"`
+	PrivateKey types.String `tfsdk:"private_key"`
+	ClientCert types.String `tfsdk:"client_cert"`
+	CaCert     types.String `tfsdk:"ca_cert"`
+
+	Metadata k0sctlSchemaClusterMetadata `tfsdk:"metadata"`
+	Spec     k0sctlSchemaModelHostsSpec  `tfsdk:"spec"`
+}
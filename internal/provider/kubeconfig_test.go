@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+func TestK0sctlKubeconfigParse(t *testing.T) {
+	// extensions, token users and exec plugins are not produced by k0s, but must not break the parsing
+	k8bytes := []byte(`
+apiVersion: v1
+kind: Config
+clusters:
+- name: k0s
+  cluster:
+    server: https://k8s.example.org:6443
+    certificate-authority-data: Y2EtY2VydA==
+    extensions:
+    - name: client.authentication.k8s.io/exec
+      extension:
+        audience: k0s
+contexts:
+- name: k0s
+  context:
+    cluster: k0s
+    user: admin
+- name: k0s-ci
+  context:
+    cluster: k0s
+    user: ci
+    namespace: ci
+current-context: k0s
+users:
+- name: admin
+  user:
+    client-certificate-data: Y2xpZW50LWNlcnQ=
+    client-key-data: Y2xpZW50LWtleQ==
+- name: ci
+  user:
+    token: abc
+- name: sso
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: kubelogin
+      args:
+      - get-token
+preferences: {}
+`)
+
+	kc, d := k0sctlKubeconfigParse(k8bytes)
+	if d.HasError() {
+		t.Fatalf("unexpected parse error: %v", d)
+	}
+
+	kcc := kc.Current()
+	if kcc.Host.ValueString() != "https://k8s.example.org:6443" {
+		t.Errorf("unexpected host: %s", kcc.Host)
+	}
+	if kcc.CaCert.ValueString() != "ca-cert" {
+		t.Errorf("unexpected ca cert: %s", kcc.CaCert)
+	}
+	if kcc.ClientCert.ValueString() != "client-cert" || kcc.PrivateKey.ValueString() != "client-key" {
+		t.Errorf("unexpected client credentials: %s %s", kcc.ClientCert, kcc.PrivateKey)
+	}
+
+	ov, d := kc.ObjectValue(context.Background())
+	if d.HasError() {
+		t.Fatalf("unexpected object conversion error: %v", d)
+	}
+
+	var m k0sctlSchemaModelKubeconfig
+	if d := ov.As(context.Background(), &m, basetypes.ObjectAsOptions{}); d.HasError() {
+		t.Fatalf("unexpected object error: %v", d)
+	}
+	if len(m.Clusters.Elements()) != 1 || len(m.Contexts.Elements()) != 2 || len(m.Users.Elements()) != 3 {
+		t.Errorf("unexpected kubeconfig entries: %d clusters, %d contexts, %d users", len(m.Clusters.Elements()), len(m.Contexts.Elements()), len(m.Users.Elements()))
+	}
+}
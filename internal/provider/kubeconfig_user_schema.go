@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func k0sctl_kubeconfig_user_schema() schema.Schema {
+	return schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Kubeconfig for a named user, created with `k0s kubeconfig create` on the leader controller.\n\n" +
+			"~> **Note:** Destroying the resource does not revoke the kubeconfig, it only removes it from state. The client certificate is signed by the cluster CA, and stays valid until `expires_at`. To take the access away before then, remove the RBAC bindings of the user and of its groups.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Kubeconfig identifier, the name of the user",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+
+			"user": schema.StringAttribute{
+				MarkdownDescription: "Name of the user, the common name of the client certificate",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"hosts": k0sctlClusterHostsAttribute(),
+			"groups": schema.ListAttribute{
+				MarkdownDescription: "Groups of the user, the organizations of the client certificate",
+				Optional:            true,
+				ElementType:         types.StringType,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+
+			"kube_yaml": schema.StringAttribute{
+				MarkdownDescription: "K8 Kubernetes API client configuration yaml file",
+				Computed:            true,
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"kube_host": schema.StringAttribute{
+				MarkdownDescription: "K8 Kubernetes API host endpoint",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"private_key": schema.StringAttribute{
+				MarkdownDescription: "K8 Private key for the user",
+				Computed:            true,
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"client_cert": schema.StringAttribute{
+				MarkdownDescription: "K8 Client certificate for the user",
+				Computed:            true,
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"ca_cert": schema.StringAttribute{
+				MarkdownDescription: "K8 Server CA certificate",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"expires_at": schema.StringAttribute{
+				MarkdownDescription: "Time when the client certificate expires (RFC3339). The kubeconfig is created again once it has expired",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+type k0sctlKubeconfigUserSchemaModel struct {
+	Id types.String `tfsdk:"id"`
+
+	User   types.String `tfsdk:"user"`
+	Hosts  types.List   `tfsdk:"hosts"`
+	Groups types.List   `tfsdk:"groups"`
+
+	KubeYaml   types.String `tfsdk:"kube_yaml"`
+	KubeHost   types.String `tfsdk:"kube_host"`
+	PrivateKey types.String `tfsdk:"private_key"`
+	ClientCert types.String `tfsdk:"client_cert"`
+	CaCert     types.String `tfsdk:"ca_cert"`
+	ExpiresAt  types.String `tfsdk:"expires_at"`
+}
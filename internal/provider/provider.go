@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -15,6 +16,7 @@ const (
 
 // Ensure K0sctlProvider satisfies various provider interfaces.
 var _ provider.Provider = &K0sctlProvider{}
+var _ provider.ProviderWithEphemeralResources = &K0sctlProvider{}
 
 // K0sctlProvider defines the provider implementation.
 type K0sctlProvider struct {
@@ -55,6 +57,7 @@ func (p *K0sctlProvider) Configure(ctx context.Context, req provider.ConfigureRe
 
 	resp.ResourceData = &data
 	resp.DataSourceData = &data
+	resp.EphemeralResourceData = &data
 
 	AllLoggingToTFLog(ctx)
 }
@@ -62,11 +65,21 @@ func (p *K0sctlProvider) Configure(ctx context.Context, req provider.ConfigureRe
 func (p *K0sctlProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewK0sctlConfigResource,
+		NewK0sctlBackupResource,
+		NewK0sctlKubeconfigUserResource,
 	}
 }
 
 func (p *K0sctlProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewK0sctlKubeconfigDataSource,
+	}
+}
+
+func (p *K0sctlProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewK0sctlKubeconfigEphemeralResource,
+	}
 }
 
 func New(version string) func() provider.Provider {
@@ -2,38 +2,551 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/ssh"
 	"gopkg.in/yaml.v2"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
-	k0s_dig "github.com/k0sproject/dig"
 	k0sctl_v1beta1 "github.com/k0sproject/k0sctl/pkg/apis/k0sctl.k0sproject.io/v1beta1"
 	k0sctl_v1beta1_cluster "github.com/k0sproject/k0sctl/pkg/apis/k0sctl.k0sproject.io/v1beta1/cluster"
 	k0s_rig "github.com/k0sproject/rig"
+	k0s_rig_agent "github.com/k0sproject/rig/pkg/ssh/agent"
 	k0sversion "github.com/k0sproject/version"
+
+	provider_action "github.com/mirantis/terraform-provider-k0sctl/internal/k0sctl/action"
+	provider_phase "github.com/mirantis/terraform-provider-k0sctl/internal/k0sctl/phase"
 )
 
 const (
 	k0sctl_schema_kind = "cluster"
+
+	k0sctl_default_version_endpoint = "https://docs.k0sproject.io"
+
+	k0sctl_default_config_schema_endpoint = "https://raw.githubusercontent.com/k0sproject/k0s"
+)
+
+var (
+	k0sctl_default_timeouts = map[string]time.Duration{
+		"create": 60 * time.Minute,
+		"update": 60 * time.Minute,
+		"delete": 20 * time.Minute,
+	}
 )
 
+// k0sctlTimeoutsBlock the timeouts block for the k0sctl operations.
+func k0sctlTimeoutsBlock() schema.Block {
+	b := timeouts.Block(context.Background(), timeouts.Opts{
+		Create:            true,
+		Update:            true,
+		Delete:            true,
+		CreateDescription: "Timeout for the k0sctl apply on create (default 60m)",
+		UpdateDescription: "Timeout for the k0sctl apply on update (default 60m)",
+		DeleteDescription: "Timeout for the k0sctl reset on destroy (default 20m)",
+	}).(schema.SingleNestedBlock)
+
+	b.MarkdownDescription = "Timeouts for the k0sctl operations, as duration strings (e.g. \"30m\"). When a timeout expires the running operation is cancelled, and the hosts are unlocked and disconnected."
+
+	return b
+}
+
+// k0sctlHookActionBlock the hooks block for a k0sctl action (apply, reset or backup), with its before and after stages.
+func k0sctlHookActionBlock(action string) schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		MarkdownDescription: fmt.Sprintf("String hooks for the host, run by the k0sctl %s operation", action),
+
+		Validators: []validator.List{
+			listvalidator.SizeAtMost(1),
+		},
+
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"before": schema.ListAttribute{
+					MarkdownDescription: fmt.Sprintf("String hooks to run on hosts before the %s operation is run.", action),
+					ElementType:         types.StringType,
+					Optional:            true,
+					Computed:            true,
+					Default:             listdefault.StaticValue(types.ListNull(types.StringType)),
+				},
+				"after": schema.ListAttribute{
+					MarkdownDescription: fmt.Sprintf("String hooks to run on hosts after the %s operation is run.", action),
+					ElementType:         types.StringType,
+					Optional:            true,
+					Computed:            true,
+					Default:             listdefault.StaticValue(types.ListNull(types.StringType)),
+				},
+			},
+		},
+	}
+}
+
+// k0sctlSSHBastionBlock the ssh bastion (jump) hosts block, used by the ssh and winrm connections.
+func k0sctlSSHBastionBlock() schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		MarkdownDescription: "SSH bastion (jump) hosts for the host. The order of the bastion blocks is the hop order: the first bastion is connected to directly, each next one through the previous one, and the host through the last one",
+
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"address": schema.StringAttribute{
+					MarkdownDescription: "Address of the bastion host, a hostname or an IP address",
+					Required:            true,
+				},
+				"key_path": schema.StringAttribute{
+					MarkdownDescription: "Path of the ssh private key file for the bastion host",
+					Optional:            true,
+				},
+				"key_content": schema.StringAttribute{
+					MarkdownDescription: "Content of the ssh key for the bastion host",
+					Optional:            true,
+					Sensitive:           true,
+				},
+				"key_passphrase": schema.StringAttribute{
+					MarkdownDescription: "Passphrase of the ssh key for the bastion host, if it is encrypted",
+					Optional:            true,
+					Sensitive:           true,
+				},
+				"use_agent": schema.BoolAttribute{
+					MarkdownDescription: "Authenticate to the bastion host with the keys of the ssh agent (SSH_AUTH_SOCK), in addition to the ssh key if any. Without it, the ssh agent is not used",
+					Optional:            true,
+				},
+				"host_key": schema.StringAttribute{
+					MarkdownDescription: "Public key the bastion host must present, in authorized_keys or known_hosts format (e.g. a line of ssh-keyscan output). When empty, the host key is checked against the known_hosts file, and unknown hosts are added to it. With trust_on_first_use, the key presented on the first connection is recorded here",
+					Optional:            true,
+					Computed:            true,
+					PlanModifiers: []planmodifier.String{
+						stringplanmodifier.UseStateForUnknown(),
+					},
+				},
+				"trust_on_first_use": schema.BoolAttribute{
+					MarkdownDescription: "Record the host key presented by the bastion host on the first connection in host_key, so that later connections fail if it presents a different key",
+					Optional:            true,
+				},
+				"user": schema.StringAttribute{
+					MarkdownDescription: "User to log in to the bastion host as",
+					Required:            true,
+				},
+				"port": schema.Int64Attribute{
+					MarkdownDescription: "SSH port of the bastion host",
+					Optional:            true,
+					Computed:            true,
+					Default:             int64default.StaticInt64(22),
+				},
+			},
+		},
+	}
+}
+
+// k0sctlMetadataBlock the cluster metadata block.
+func k0sctlMetadataBlock() schema.SingleNestedBlock {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Metadata for the launchpad cluster",
+
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Cluster name",
+				Required:            true,
+			},
+		},
+	}
+}
+
+// k0sctlHostsSpecBlock the spec block of the resources which connect to an existing cluster, with only the host blocks.
+func k0sctlHostsSpecBlock() schema.SingleNestedBlock {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Hosts of the cluster to connect to, configured as in the spec of the k0sctl_config resource",
+
+		Validators: []validator.Object{
+			objectvalidator.IsRequired(),
+		},
+
+		Blocks: map[string]schema.Block{
+			"host": k0sctlHostBlock(),
+		},
+	}
+}
+
+// k0sctlClusterHostsAttribute the hosts of a cluster managed by a k0sctl_config resource, referenced from its
+// spec.host, for the resources which act on that cluster. A different cluster is a new resource.
+func k0sctlClusterHostsAttribute() schema.ListAttribute {
+	return schema.ListAttribute{
+		MarkdownDescription: "Hosts of the cluster, as managed by a k0sctl_config resource (e.g. `k0sctl_config.cluster.spec.host`), so that the connection settings and the recorded host keys are those of the cluster",
+		ElementType:         k0sctlHostBlock().NestedObject.Type(),
+		Required:            true,
+		Sensitive:           true,
+		Validators: []validator.List{
+			listvalidator.SizeAtLeast(1),
+		},
+		PlanModifiers: []planmodifier.List{
+			listplanmodifier.RequiresReplace(),
+		},
+	}
+}
+
+// k0sctlClusterHosts build a k0sctl cluster configuration struct of the hosts of a k0sctl_config resource, to connect to the cluster.
+func k0sctlClusterHosts(ctx context.Context, hosts types.List, name string) (k0sctl_v1beta1.Cluster, diag.Diagnostics) {
+	var hs k0sctlSchemaModelHostsSpec
+
+	if d := hosts.ElementsAs(ctx, &hs.Hosts, false); d.HasError() {
+		return k0sctl_v1beta1.Cluster{}, d
+	}
+
+	return hs.Cluster(ctx, name)
+}
+
+// k0sctlHostBlock the host block, for each machine in the cluster.
+func k0sctlHostBlock() schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		MarkdownDescription: "Individual host configuration, for each machine in the cluster",
+
+		Validators: []validator.List{
+			listvalidator.SizeAtLeast(1),
+		},
+
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"role": schema.StringAttribute{
+					MarkdownDescription: "Host machine role in the cluster, one of controller, worker, controller+worker or single",
+					Required:            true,
+					Validators: []validator.String{
+						stringvalidator.OneOf("controller", "worker", "controller+worker", "single"),
+					},
+				},
+
+				"install_flags": schema.ListAttribute{
+					MarkdownDescription: "String install flags passed to k0s (e.g. '--taints=mytaint')",
+					Optional:            true,
+					ElementType:         types.StringType,
+				},
+				"hostname": schema.StringAttribute{
+					MarkdownDescription: "Hostname override for the host",
+					Optional:            true,
+				},
+				"private_address": schema.StringAttribute{
+					MarkdownDescription: "Private address override for the host",
+					Optional:            true,
+				},
+				"no_taints": schema.BoolAttribute{
+					MarkdownDescription: "Do not apply taints to the host, used in conjunction with the controller+worker role",
+					Optional:            true,
+				},
+				"upload_binary": schema.BoolAttribute{
+					MarkdownDescription: "Download the k0s binary on the terraform runner and upload it to the host, instead of downloading it on the host",
+					Optional:            true,
+				},
+				"k0s_binary_path": schema.StringAttribute{
+					MarkdownDescription: "Local path of a k0s binary on the terraform runner, to upload to the host",
+					Optional:            true,
+					Validators: []validator.String{
+						stringvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("k0s_download_url")),
+					},
+				},
+				"k0s_download_url": schema.StringAttribute{
+					MarkdownDescription: "URL to download the k0s binary from, e.g. an internal mirror, on the terraform runner when upload_binary is set, otherwise on the host. The tokens %v (version), %p (architecture) and %x (.exe on windows) are expanded",
+					Optional:            true,
+				},
+				"environment": schema.MapAttribute{
+					MarkdownDescription: "Environment variables set for the k0s service and the k0sctl commands on the host (e.g. HTTP_PROXY)",
+					Optional:            true,
+					ElementType:         types.StringType,
+				},
+			},
+
+			Blocks: map[string]schema.Block{
+
+				"files": schema.ListNestedBlock{
+					MarkdownDescription: "Files to upload to the host, before k0s is installed",
+
+					NestedObject: schema.NestedBlockObject{
+						Attributes: map[string]schema.Attribute{
+							"name": schema.StringAttribute{
+								MarkdownDescription: "Name of the file, used in logs",
+								Optional:            true,
+							},
+							"src": schema.StringAttribute{
+								MarkdownDescription: "Local path of the file, a directory or a glob pattern to upload from the terraform runner, or a URL to download on the host",
+								Optional:            true,
+								Validators: []validator.String{
+									stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("content")),
+								},
+							},
+							"content": schema.StringAttribute{
+								MarkdownDescription: "Inline content of the file, e.g. rendered by terraform",
+								Optional:            true,
+							},
+							"dst": schema.StringAttribute{
+								MarkdownDescription: "Destination file path on the host",
+								Optional:            true,
+							},
+							"dst_dir": schema.StringAttribute{
+								MarkdownDescription: "Destination directory on the host",
+								Optional:            true,
+							},
+							"perm": schema.StringAttribute{
+								MarkdownDescription: "File permission mode (e.g. \"0644\")",
+								Optional:            true,
+							},
+							"dir_perm": schema.StringAttribute{
+								MarkdownDescription: "Permission mode of created directories (e.g. \"0755\")",
+								Optional:            true,
+							},
+							"user": schema.StringAttribute{
+								MarkdownDescription: "Owner of the file",
+								Optional:            true,
+							},
+							"group": schema.StringAttribute{
+								MarkdownDescription: "Group of the file",
+								Optional:            true,
+							},
+						},
+					},
+				},
+
+				"hooks": schema.ListNestedBlock{
+					MarkdownDescription: "Hook configuration for the host",
+
+					Validators: []validator.List{
+						listvalidator.SizeAtMost(1),
+					},
+
+					NestedObject: schema.NestedBlockObject{
+						Attributes: map[string]schema.Attribute{},
+						Blocks: map[string]schema.Block{
+
+							"apply":  k0sctlHookActionBlock("apply"),
+							"reset":  k0sctlHookActionBlock("reset"),
+							"backup": k0sctlHookActionBlock("backup"),
+						},
+					},
+				},
+
+				"ssh": schema.ListNestedBlock{
+					MarkdownDescription: "SSH configuration for the host",
+
+					Validators: []validator.List{
+						listvalidator.SizeAtMost(1),
+						listvalidator.ConflictsWith(
+							path.MatchRelative().AtParent().AtName("winrm"),
+							path.MatchRelative().AtParent().AtName("openssh"),
+							path.MatchRelative().AtParent().AtName("localhost"),
+						),
+					},
+
+					NestedObject: schema.NestedBlockObject{
+						Attributes: map[string]schema.Attribute{
+							"address": schema.StringAttribute{
+								MarkdownDescription: "SSH endpoint",
+								Required:            true,
+							},
+							"key_path": schema.StringAttribute{
+								MarkdownDescription: "Path of the ssh private key file",
+								Optional:            true,
+							},
+							"key_content": schema.StringAttribute{
+								MarkdownDescription: "Content of the ssh key",
+								Optional:            true,
+								Sensitive:           true,
+							},
+							"key_passphrase": schema.StringAttribute{
+								MarkdownDescription: "Passphrase of the ssh key, if it is encrypted",
+								Optional:            true,
+								Sensitive:           true,
+							},
+							"use_agent": schema.BoolAttribute{
+								MarkdownDescription: "Authenticate with the keys of the ssh agent (SSH_AUTH_SOCK), in addition to the ssh key if any. Without it, the ssh agent is not used",
+								Optional:            true,
+							},
+							"host_key": schema.StringAttribute{
+								MarkdownDescription: "Public key the host must present, in authorized_keys or known_hosts format (e.g. a line of ssh-keyscan output). When empty, the host key is checked against the known_hosts file, and unknown hosts are added to it. With trust_on_first_use, the key presented on the first connection is recorded here",
+								Optional:            true,
+								Computed:            true,
+								PlanModifiers: []planmodifier.String{
+									stringplanmodifier.UseStateForUnknown(),
+								},
+							},
+							"trust_on_first_use": schema.BoolAttribute{
+								MarkdownDescription: "Record the host key presented on the first connection in host_key, so that later connections fail if the host presents a different key",
+								Optional:            true,
+							},
+							"user": schema.StringAttribute{
+								MarkdownDescription: "User to log in to the host as",
+								Required:            true,
+							},
+							"port": schema.Int64Attribute{
+								MarkdownDescription: "SSH Port",
+								Optional:            true,
+								Computed:            true,
+								Default:             int64default.StaticInt64(22),
+							},
+						},
+
+						Blocks: map[string]schema.Block{
+							"bastion": k0sctlSSHBastionBlock(),
+						},
+					},
+				},
+				"openssh": schema.ListNestedBlock{
+					MarkdownDescription: "OpenSSH configuration for the host, which uses the system ssh client and honours its configuration (e.g. ~/.ssh/config, ProxyJump, ControlMaster)",
+
+					Validators: []validator.List{
+						listvalidator.SizeAtMost(1),
+						listvalidator.ConflictsWith(
+							path.MatchRelative().AtParent().AtName("ssh"),
+							path.MatchRelative().AtParent().AtName("winrm"),
+							path.MatchRelative().AtParent().AtName("localhost"),
+						),
+					},
+
+					NestedObject: schema.NestedBlockObject{
+						Attributes: map[string]schema.Attribute{
+							"address": schema.StringAttribute{
+								MarkdownDescription: "SSH endpoint, or a host alias from the ssh configuration",
+								Required:            true,
+							},
+							"user": schema.StringAttribute{
+								MarkdownDescription: "SSH user, if not set in the ssh configuration",
+								Optional:            true,
+							},
+							"port": schema.Int64Attribute{
+								MarkdownDescription: "SSH Port, if not set in the ssh configuration",
+								Optional:            true,
+							},
+							"key_path": schema.StringAttribute{
+								MarkdownDescription: "Path to the ssh key, if not set in the ssh configuration",
+								Optional:            true,
+							},
+							"config_path": schema.StringAttribute{
+								MarkdownDescription: "Path to the ssh configuration file (default ~/.ssh/config)",
+								Optional:            true,
+							},
+							"options": schema.MapAttribute{
+								MarkdownDescription: "Additional ssh options passed with -o (e.g. StrictHostKeyChecking = \"no\")",
+								Optional:            true,
+								ElementType:         types.StringType,
+							},
+							"disable_multiplexing": schema.BoolAttribute{
+								MarkdownDescription: "Do not reuse a single ssh connection (ControlMaster) for the commands run on the host",
+								Optional:            true,
+							},
+						},
+					},
+				},
+				"localhost": schema.ListNestedBlock{
+					MarkdownDescription: "Localhost connection, to install k0s on the terraform runner itself",
+
+					Validators: []validator.List{
+						listvalidator.SizeAtMost(1),
+						listvalidator.ConflictsWith(
+							path.MatchRelative().AtParent().AtName("ssh"),
+							path.MatchRelative().AtParent().AtName("winrm"),
+						),
+					},
+
+					// the block itself enables the connection, it has no attributes
+					NestedObject: schema.NestedBlockObject{},
+				},
+				"winrm": schema.ListNestedBlock{
+					MarkdownDescription: "WinRM configuration for the host",
+
+					NestedObject: schema.NestedBlockObject{
+						Attributes: map[string]schema.Attribute{
+							"address": schema.StringAttribute{
+								MarkdownDescription: "WinRM endpoint",
+								Required:            true,
+							},
+							"user": schema.StringAttribute{
+								MarkdownDescription: "WinRM user",
+								Required:            true,
+							},
+							"password": schema.StringAttribute{
+								MarkdownDescription: "WinRM password, not needed with certificate authentication",
+								Optional:            true,
+								Sensitive:           true,
+							},
+							"port": schema.Int64Attribute{
+								MarkdownDescription: "WinRM Port",
+								Optional:            true,
+								Computed:            true,
+								Default:             int64default.StaticInt64(5985),
+							},
+							"use_https": schema.BoolAttribute{
+								MarkdownDescription: "If false, then no HTTP is used for winrm transport",
+								Optional:            true,
+								Computed:            true,
+								Default:             booldefault.StaticBool(true),
+							},
+							"insecure": schema.BoolAttribute{
+								MarkdownDescription: "If false, then no SSL certificate validation is used",
+								Optional:            true,
+								Computed:            true,
+								Default:             booldefault.StaticBool(true),
+							},
+							"use_ntlm": schema.BoolAttribute{
+								MarkdownDescription: "Authenticate with NTLM instead of basic authentication",
+								Optional:            true,
+								Computed:            true,
+								Default:             booldefault.StaticBool(false),
+							},
+							"ca_cert_path": schema.StringAttribute{
+								MarkdownDescription: "Path to the CA certificate to verify the WinRM endpoint certificate with",
+								Optional:            true,
+							},
+							"cert_path": schema.StringAttribute{
+								MarkdownDescription: "Path to the client certificate, for certificate authentication over https",
+								Optional:            true,
+							},
+							"key_path": schema.StringAttribute{
+								MarkdownDescription: "Path to the private key of the client certificate",
+								Optional:            true,
+							},
+							"tls_server_name": schema.StringAttribute{
+								MarkdownDescription: "Server name to verify the WinRM endpoint certificate against, if it differs from the address (e.g. when connecting through a bastion)",
+								Optional:            true,
+							},
+						},
+
+						Blocks: map[string]schema.Block{
+							"bastion": k0sctlSSHBastionBlock(),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func k0sctl_v1beta1_schema() schema.Schema {
 	return schema.Schema{
 		// This description is used by the documentation generator and the language server.
-		MarkdownDescription: "Mirantis installation using launchpad, parametrized",
+		MarkdownDescription: "Mirantis installation using launchpad, parametrized\n\n" +
+			"~> **Note:** The admin kubeconfig of the cluster (`kube_yaml`, `kubeconfig`, `private_key` and `client_cert`) is always stored in the state, whether it is used or not. Sensitive values are only hidden in the plan output. Configure other providers with the `k0sctl_kubeconfig` ephemeral resource (Terraform 1.10+), so that the credentials are not copied into further state, and protect the state of this resource as a secret (e.g. with an encrypted backend).",
+
+		// version 1 changed spec.k0s.config from a yaml string to a dynamic value
+		Version: 1,
 
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -82,11 +595,47 @@ func k0sctl_v1beta1_schema() schema.Schema {
 				Computed:            true,
 				Default:             booldefault.StaticBool(false),
 			},
+			"dry_run": schema.BoolAttribute{
+				MarkdownDescription: "Run the k0sctl apply in dry-run mode, which reports the operations it would perform on the hosts in dry_run_report, without changing anything. The dry run is run when applying, planning doesn't connect to the hosts. As nothing is changed, the prior state is restored on the next refresh, so that the changes are planned again",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"dry_run_report": schema.ListNestedAttribute{
+				MarkdownDescription: "Operations which the dry run would perform on each host",
+				Computed:            true,
+
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"host": schema.StringAttribute{
+							MarkdownDescription: "Host address",
+							Computed:            true,
+						},
+						"role": schema.StringAttribute{
+							MarkdownDescription: "Host machine role in the cluster",
+							Computed:            true,
+						},
+						"operation": schema.StringAttribute{
+							MarkdownDescription: "Operation which would be performed on the host: install, upgrade, reset or no-op",
+							Computed:            true,
+						},
+						"running_version": schema.StringAttribute{
+							MarkdownDescription: "K0s version running on the host, if any",
+							Computed:            true,
+						},
+					},
+				},
+			},
 			"restore_from": schema.StringAttribute{
 				MarkdownDescription: "Path to cluster backup archive to restore the state from",
 				Optional:            true,
 			},
 
+			"kubeconfig_api_address": schema.StringAttribute{
+				MarkdownDescription: "Address (host name or IP) of the Kubernetes API to use in the kubeconfig, e.g. a load balancer. Defaults to the api.externalAddress of the k0s config if set, otherwise the address of the first controller",
+				Optional:            true,
+			},
+
 			"kube_skiptlsverify": schema.BoolAttribute{
 				MarkdownDescription: "K8 Kubernetes endpoint TLS should not be verified",
 				Optional:            true,
@@ -95,12 +644,12 @@ func k0sctl_v1beta1_schema() schema.Schema {
 			},
 
 			"k0s_yaml": schema.StringAttribute{
-				MarkdownDescription: "K0S yaml for debugging ",
+				MarkdownDescription: "K0S yaml for debugging, with the winrm passwords, environment values and file contents of the hosts redacted",
 				Computed:            true,
 			},
 
 			"kube_yaml": schema.StringAttribute{
-				MarkdownDescription: "K8 Kubernetes API client configuration yaml file",
+				MarkdownDescription: "K8 Kubernetes API client configuration yaml file, with the admin credentials. Stored in the state, see the note above",
 				Computed:            true,
 				Sensitive:           true,
 			},
@@ -111,242 +660,197 @@ func k0sctl_v1beta1_schema() schema.Schema {
 			},
 
 			"private_key": schema.StringAttribute{
-				MarkdownDescription: "K8 Private key for the user",
+				MarkdownDescription: "K8 Private key for the user, stored in the state like kube_yaml",
 				Computed:            true,
+				Sensitive:           true,
 			},
 			"client_cert": schema.StringAttribute{
-				MarkdownDescription: "K8 Client certificate for the user",
+				MarkdownDescription: "K8 Client certificate for the user, stored in the state like kube_yaml",
 				Computed:            true,
+				Sensitive:           true,
 			},
 			"ca_cert": schema.StringAttribute{
 				MarkdownDescription: "K8 Server CA certificate",
 				Computed:            true,
 			},
-		},
-
-		Blocks: map[string]schema.Block{
 
-			"metadata": schema.SingleNestedBlock{
-				MarkdownDescription: "Metadata for the launchpad cluster",
+			"kubeconfig": schema.SingleNestedAttribute{
+				MarkdownDescription: "K8 Kubernetes API client configuration, with every cluster, context and user of kube_yaml. Stored in the state like kube_yaml",
+				Computed:            true,
+				Sensitive:           true,
 
 				Attributes: map[string]schema.Attribute{
-					"name": schema.StringAttribute{
-						MarkdownDescription: "Cluster name",
-						Required:            true,
-					},
-				},
-			},
-
-			"spec": schema.SingleNestedBlock{
-				MarkdownDescription: "Launchpad install specifications",
-
-				Blocks: map[string]schema.Block{
-
-					"k0s": schema.SingleNestedBlock{
-						MarkdownDescription: "K0S installation configuration",
-
-						Attributes: map[string]schema.Attribute{
-							"version": schema.StringAttribute{
-								MarkdownDescription: "K0s version to install",
-								Required:            true,
-							},
-
-							"config": schema.StringAttribute{
-								MarkdownDescription: "K0s config yaml as a string",
-								Optional:            true,
-							},
-						},
+					"current_context": schema.StringAttribute{
+						MarkdownDescription: "Name of the context used by default",
+						Computed:            true,
 					},
+					"clusters": schema.ListNestedAttribute{
+						MarkdownDescription: "Kubernetes API endpoints",
+						Computed:            true,
 
-					"host": schema.ListNestedBlock{
-						MarkdownDescription: "Individual host configuration, for each machine in the cluster",
-
-						Validators: []validator.List{
-							listvalidator.SizeAtLeast(1),
-						},
-
-						NestedObject: schema.NestedBlockObject{
+						NestedObject: schema.NestedAttributeObject{
 							Attributes: map[string]schema.Attribute{
-								"role": schema.StringAttribute{
-									MarkdownDescription: "Host machine role in the cluster",
-									Required:            true,
+								"name": schema.StringAttribute{
+									MarkdownDescription: "Cluster name",
+									Computed:            true,
 								},
-
-								"install_flags": schema.ListAttribute{
-									MarkdownDescription: "String install flags passed to k0s (e.g. '--taints=mytaint')",
-									Optional:            true,
-									ElementType:         types.StringType,
+								"server": schema.StringAttribute{
+									MarkdownDescription: "Kubernetes API host endpoint",
+									Computed:            true,
+								},
+								"ca_cert": schema.StringAttribute{
+									MarkdownDescription: "Server CA certificate (PEM)",
+									Computed:            true,
 								},
-								"hostname": schema.StringAttribute{
-									MarkdownDescription: "Hostname override for the host",
-									Optional:            true,
+								"tls_server_name": schema.StringAttribute{
+									MarkdownDescription: "Server name used to verify the server certificate",
+									Computed:            true,
 								},
-								"private_address": schema.StringAttribute{
-									MarkdownDescription: "Private address override for the host",
-									Optional:            true,
+								"insecure_skip_tls_verify": schema.BoolAttribute{
+									MarkdownDescription: "Server certificate is not verified",
+									Computed:            true,
 								},
-								"no_taints": schema.BoolAttribute{
-									MarkdownDescription: "Do not apply taints to the host, used in conjunction with the controller+worker role",
-									Optional:            true,
+								"proxy_url": schema.StringAttribute{
+									MarkdownDescription: "Proxy used to reach the Kubernetes API",
+									Computed:            true,
 								},
 							},
+						},
+					},
+					"contexts": schema.ListNestedAttribute{
+						MarkdownDescription: "Pairs of cluster and user",
+						Computed:            true,
 
-							Blocks: map[string]schema.Block{
-
-								"hooks": schema.ListNestedBlock{
-									MarkdownDescription: "Hook configuration for the host",
-
-									Validators: []validator.List{
-										listvalidator.SizeAtMost(1),
-									},
-
-									NestedObject: schema.NestedBlockObject{
-										Attributes: map[string]schema.Attribute{},
-										Blocks: map[string]schema.Block{
-
-											"apply": schema.ListNestedBlock{
-												MarkdownDescription: "Launchpad.Apply string hooks for the host",
-
-												Validators: []validator.List{
-													listvalidator.SizeAtMost(1),
-												},
-
-												NestedObject: schema.NestedBlockObject{
-													Attributes: map[string]schema.Attribute{
-														"before": schema.ListAttribute{
-															MarkdownDescription: "String hooks to run on hosts before the Apply operation is run.",
-															ElementType:         types.StringType,
-															Optional:            true,
-															Computed:            true,
-															Default:             listdefault.StaticValue(types.ListNull(types.StringType)),
-														},
-														"after": schema.ListAttribute{
-															MarkdownDescription: "String hooks to run on hosts after the Apply operation is run.",
-															ElementType:         types.StringType,
-															Optional:            true,
-															Computed:            true,
-															Default:             listdefault.StaticValue(types.ListNull(types.StringType)),
-														},
-													},
-												},
-											},
-										},
-									},
+						NestedObject: schema.NestedAttributeObject{
+							Attributes: map[string]schema.Attribute{
+								"name": schema.StringAttribute{
+									MarkdownDescription: "Context name",
+									Computed:            true,
 								},
-
-								"ssh": schema.ListNestedBlock{
-									MarkdownDescription: "SSH configuration for the host",
-
-									NestedObject: schema.NestedBlockObject{
-										Attributes: map[string]schema.Attribute{
-											"address": schema.StringAttribute{
-												MarkdownDescription: "SSH endpoint",
-												Required:            true,
-											},
-											"key_path": schema.StringAttribute{
-												MarkdownDescription: "SSH endpoint",
-												Optional:            true,
-											},
-											"key_content": schema.StringAttribute{
-												MarkdownDescription: "Content of the ssh key",
-												Optional:            true,
-											},
-											"user": schema.StringAttribute{
-												MarkdownDescription: "SSH endpoint",
-												Required:            true,
-											},
-											"port": schema.Int64Attribute{
-												MarkdownDescription: "SSH Port",
-												Optional:            true,
-												Computed:            true,
-												Default:             int64default.StaticInt64(22),
-											},
-										},
-
-										Blocks: map[string]schema.Block{
-											"bastion": schema.ListNestedBlock{
-												MarkdownDescription: "SSH bastion configuration for the host",
-
-												Validators: []validator.List{
-													listvalidator.SizeAtMost(1),
-												},
-
-												NestedObject: schema.NestedBlockObject{
-													Attributes: map[string]schema.Attribute{
-														"address": schema.StringAttribute{
-															MarkdownDescription: "bastion endpoint",
-															Required:            true,
-														},
-														"key_path": schema.StringAttribute{
-															MarkdownDescription: "bastion endpoint",
-															Optional:            true,
-														},
-														"key_content": schema.StringAttribute{
-															MarkdownDescription: "Content of the ssh key for the bastion host",
-															Optional:            true,
-														},
-														"user": schema.StringAttribute{
-															MarkdownDescription: "bastion endpoint",
-															Required:            true,
-														},
-														"port": schema.Int64Attribute{
-															MarkdownDescription: "bastion Port",
-															Optional:            true,
-															Computed:            true,
-															Default:             int64default.StaticInt64(22),
-														},
-													},
-												},
-											},
-										},
-									},
+								"cluster": schema.StringAttribute{
+									MarkdownDescription: "Name of the cluster of the context",
+									Computed:            true,
 								},
-								"winrm": schema.ListNestedBlock{
-									MarkdownDescription: "WinRM configuration for the host",
-
-									NestedObject: schema.NestedBlockObject{
-										Attributes: map[string]schema.Attribute{
-											"address": schema.StringAttribute{
-												MarkdownDescription: "WinRM endpoint",
-												Required:            true,
-											},
-											"user": schema.StringAttribute{
-												MarkdownDescription: "WinRM user",
-												Required:            true,
-											},
-											"password": schema.StringAttribute{
-												MarkdownDescription: "WinRM password",
-												Required:            true,
-											},
-											"port": schema.Int64Attribute{
-												MarkdownDescription: "WinRM Port",
-												Optional:            true,
-												Computed:            true,
-												Default:             int64default.StaticInt64(5985),
-											},
-											"use_https": schema.BoolAttribute{
-												MarkdownDescription: "If false, then no HTTP is used for winrm transport",
-												Optional:            true,
-												Computed:            true,
-												Default:             booldefault.StaticBool(true),
-											},
-											"insecure": schema.BoolAttribute{
-												MarkdownDescription: "If false, then no SSL certificate validation is used",
-												Optional:            true,
-												Computed:            true,
-												Default:             booldefault.StaticBool(true),
-											},
-										},
-									},
+								"user": schema.StringAttribute{
+									MarkdownDescription: "Name of the user of the context",
+									Computed:            true,
+								},
+								"namespace": schema.StringAttribute{
+									MarkdownDescription: "Default namespace of the context",
+									Computed:            true,
 								},
 							},
 						},
 					},
-				},
-			},
-		},
-	}
-}
+					"users": schema.ListNestedAttribute{
+						MarkdownDescription: "Client credentials",
+						Computed:            true,
+
+						NestedObject: schema.NestedAttributeObject{
+							Attributes: map[string]schema.Attribute{
+								"name": schema.StringAttribute{
+									MarkdownDescription: "User name in the kubeconfig",
+									Computed:            true,
+								},
+								"client_cert": schema.StringAttribute{
+									MarkdownDescription: "Client certificate (PEM)",
+									Computed:            true,
+								},
+								"private_key": schema.StringAttribute{
+									MarkdownDescription: "Client private key (PEM)",
+									Computed:            true,
+								},
+								"token": schema.StringAttribute{
+									MarkdownDescription: "Bearer token",
+									Computed:            true,
+								},
+								"username": schema.StringAttribute{
+									MarkdownDescription: "Basic authentication user name",
+									Computed:            true,
+								},
+								"password": schema.StringAttribute{
+									MarkdownDescription: "Basic authentication password",
+									Computed:            true,
+								},
+								"exec_command": schema.StringAttribute{
+									MarkdownDescription: "Command of the exec credential plugin, if the user authenticates with one",
+									Computed:            true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+
+			"timeouts": k0sctlTimeoutsBlock(),
+
+			"metadata": k0sctlMetadataBlock(),
+
+			"spec": schema.SingleNestedBlock{
+				MarkdownDescription: "Launchpad install specifications",
+
+				Blocks: map[string]schema.Block{
+
+					"k0s": schema.SingleNestedBlock{
+						MarkdownDescription: "K0S installation configuration",
+
+						Attributes: map[string]schema.Attribute{
+							"version": schema.StringAttribute{
+								MarkdownDescription: "K0s version to install. When empty, the latest version of the version_channel is installed. Upgrades which skip a Kubernetes minor version, and downgrades without disable_downgrade_check, are refused when planning",
+								Optional:            true,
+							},
+							"version_channel": schema.StringAttribute{
+								MarkdownDescription: "Release channel to look up the k0s version in when no version is given, either stable or latest (including pre-releases)",
+								Optional:            true,
+								Computed:            true,
+								Default:             stringdefault.StaticString("stable"),
+								Validators: []validator.String{
+									stringvalidator.OneOf("stable", "latest"),
+								},
+							},
+							"version_endpoint": schema.StringAttribute{
+								MarkdownDescription: "Base URL to look up the k0s version of the version_channel at (from stable.txt or latest.txt), e.g. an internal mirror",
+								Optional:            true,
+								Computed:            true,
+								Default:             stringdefault.StaticString(k0sctl_default_version_endpoint),
+							},
+							"config_schema_endpoint": schema.StringAttribute{
+								MarkdownDescription: "Base URL of the k0s sources to download the ClusterConfig schema of the resolved_version from (the ClusterConfig CRD under `<version>/" + k0sctlK0sConfigSchemaPath + "`), e.g. an internal mirror. An empty string disables the validation of the config when planning",
+								Optional:            true,
+								Computed:            true,
+								Default:             stringdefault.StaticString(k0sctl_default_config_schema_endpoint),
+							},
+							"resolved_version": schema.StringAttribute{
+								MarkdownDescription: "K0s version which is installed, the version or the latest version of the version_channel. The version_channel is looked up when planning the creation, a change of the version_channel or version_endpoint, or a correction of the version running on the hosts",
+								Computed:            true,
+							},
+							"dynamic_config": schema.BoolAttribute{
+								MarkdownDescription: "Enable the k0s dynamic config, so that the cluster wide config is managed as a ClusterConfig in the cluster, and only the node config is written to the controllers",
+								Optional:            true,
+								Computed:            true,
+								Default:             booldefault.StaticBool(false),
+							},
+
+							"config": schema.DynamicAttribute{
+								MarkdownDescription: "K0s config, either as an object (e.g. `{ spec = { network = { provider = \"calico\" } } }`) or as a yaml string. Changes which don't alter the config (e.g. yaml formatting) are not planned. The config is validated when planning against the ClusterConfig v1beta1 schema of the resolved_version, downloaded from the config_schema_endpoint. Fields which are not in the schema are only warned about",
+								Optional:            true,
+								Computed:            true,
+								PlanModifiers: []planmodifier.Dynamic{
+									k0sctlK0sConfigSemanticEquality(),
+								},
+							},
+						},
+					},
+
+					"host": k0sctlHostBlock(),
+				},
+			},
+		},
+	}
+}
 
 type k0sctlSchemaModel struct {
 	Id          types.String `tfsdk:"id"`
@@ -360,20 +864,43 @@ type k0sctlSchemaModel struct {
 
 	RestoreFrom types.String `tfsdk:"restore_from"`
 
+	DryRun       types.Bool `tfsdk:"dry_run"`
+	DryRunReport types.List `tfsdk:"dry_run_report"`
+
 	K0sYaml types.String `tfsdk:"k0s_yaml"`
 
 	PrivateKey types.String `tfsdk:"private_key"`
 	ClientCert types.String `tfsdk:"client_cert"`
 	CaCert     types.String `tfsdk:"ca_cert"`
 
+	Kubeconfig types.Object `tfsdk:"kubeconfig"`
+
 	KubeYaml          types.String `tfsdk:"kube_yaml"`
 	KubeHost          types.String `tfsdk:"kube_host"`
 	KubeSkipTLSVerify types.Bool   `tfsdk:"kube_skiptlsverify"`
 
+	KubeconfigAPIAddress types.String `tfsdk:"kubeconfig_api_address"`
+
+	Timeouts timeouts.Value              `tfsdk:"timeouts"`
 	Metadata k0sctlSchemaClusterMetadata `tfsdk:"metadata"`
 	Spec     k0sctlSchemaModelSpec       `tfsdk:"spec"`
 }
 
+// APIAddress the API address to use in the kubeconfig, from the override or the k0s config api.externalAddress.
+//
+// An empty string lets k0sctl use the address of the first controller.
+func (ksm *k0sctlSchemaModel) APIAddress(c k0sctl_v1beta1.Cluster) string {
+	if a := ksm.KubeconfigAPIAddress.ValueString(); a != "" {
+		return a
+	}
+
+	if c.Spec == nil || c.Spec.K0s == nil {
+		return ""
+	}
+
+	return c.Spec.K0s.Config.DigString("spec", "api", "externalAddress")
+}
+
 // Cluster build a k0sctl cluster configuration struct from the model data.
 func (ksm *k0sctlSchemaModel) Cluster(ctx context.Context) (k0sctl_v1beta1.Cluster, diag.Diagnostics) {
 	tflog.Info(ctx, "Creating k0sctl Cluster from schema", map[string]interface{}{})
@@ -383,8 +910,19 @@ func (ksm *k0sctlSchemaModel) Cluster(ctx context.Context) (k0sctl_v1beta1.Clust
 
 	var v *k0sversion.Version
 
-	if vv, err := k0sversion.NewVersion(ksm.Spec.K0s.Version.ValueString()); err != nil {
-		d.AddError("Could not interpret version", "Passed K0s version could not be parsed")
+	if ksm.Spec.K0s.Version.IsUnknown() {
+		// the version is not known until apply, so it can't be validated yet
+	} else if ksm.Spec.K0s.Version.IsNull() {
+		// without a version, the version resolved from the version channel is installed
+		if rv := ksm.Spec.K0s.ResolvedVersion.ValueString(); rv != "" {
+			if vv, err := k0sversion.NewVersion(rv); err != nil {
+				d.AddAttributeError(path.Root("spec").AtName("k0s").AtName("resolved_version"), "Could not interpret the resolved version", err.Error())
+			} else {
+				v = vv
+			}
+		}
+	} else if vv, err := k0sversion.NewVersion(ksm.Spec.K0s.Version.ValueString()); err != nil {
+		d.AddAttributeError(path.Root("spec").AtName("k0s").AtName("version"), "Could not interpret version", "Passed K0s version could not be parsed")
 	} else {
 		v = vv
 	}
@@ -400,142 +938,183 @@ func (ksm *k0sctlSchemaModel) Cluster(ctx context.Context) (k0sctl_v1beta1.Clust
 		Spec: &k0sctl_v1beta1_cluster.Spec{
 			Hosts: k0sctl_v1beta1_cluster.Hosts{},
 			K0s: &k0sctl_v1beta1_cluster.K0s{
-				Version: v,
+				Version:        v,
+				VersionChannel: ksm.Spec.K0s.VersionChannel.ValueString(),
+				DynamicConfig:  ksm.Spec.K0s.DynamicConfig.ValueBool(),
 			},
 		},
 	}
 
-	if kc := ksm.Spec.K0s.Config.ValueString(); kc != "" {
-		// if k0s.Config is not empty, then it needs to be
-		// converted to a dig.Mapping
-		var dm k0s_dig.Mapping
-
-		if err := yaml.Unmarshal([]byte(kc), &dm); err != nil {
-			d.AddWarning("K0s config unmarshal failed", err.Error())
-		} else {
-			c.Spec.K0s.Config = dm
-		}
+	if dm, err := k0sctlK0sConfigMapping(ksm.Spec.K0s.Config); errors.Is(err, errK0sctlK0sConfigUnknown) {
+		// the config is not known until apply, so it can't be converted yet
+	} else if err != nil {
+		d.AddAttributeError(path.Root("spec").AtName("k0s").AtName("config"), "K0s config unmarshal failed", err.Error())
+	} else {
+		c.Spec.K0s.Config = dm
 	}
 
-	for _, sh := range ksm.Spec.Hosts {
+	for i, sh := range ksm.Spec.Hosts {
+		shp := path.Root("spec").AtName("host").AtListIndex(i)
+
 		h := k0sctl_v1beta1_cluster.Host{
 			Role:             sh.Role.ValueString(),
 			Hooks:            k0sctl_v1beta1_cluster.Hooks{},
 			PrivateAddress:   sh.PrivateAddress.ValueString(),
 			HostnameOverride: sh.Hostname.ValueString(),
 			NoTaints:         sh.NoTaints.ValueBool(),
+			UploadBinary:     sh.UploadBinary.ValueBool(),
+			K0sBinaryPath:    sh.K0sBinaryPath.ValueString(),
+			K0sDownloadURL:   sh.K0sDownloadURL.ValueString(),
 		}
 
 		if len(sh.InstallFlags) > 0 {
 			var shifs = make([]string, len(sh.InstallFlags))
-			for i, shif := range sh.InstallFlags {
-				shifs[i] = shif.ValueString()
+			for j, shif := range sh.InstallFlags {
+				shifs[j] = shif.ValueString()
 			}
 			h.InstallFlags = k0sctl_v1beta1_cluster.Flags(shifs)
 		}
+		if !sh.Environment.IsNull() && !sh.Environment.IsUnknown() {
+			d.Append(sh.Environment.ElementsAs(ctx, &h.Environment, false)...)
+		}
+
+		for j, shf := range sh.Files {
+			shfp := shp.AtName("files").AtListIndex(j)
+
+			if shf.Src.IsUnknown() || shf.Content.IsUnknown() {
+				// the file is not known until apply, so it can't be resolved yet
+				continue
+			}
+
+			if uf, err := shf.UploadFile(); err != nil {
+				d.AddAttributeError(shfp.AtName("src"), "Could not resolve the file to upload", err.Error())
+			} else {
+				h.Files = append(h.Files, uf)
+			}
+		}
 		if len(sh.SSH) > 0 {
 			shssh := sh.SSH[0]
-			var authMethods []ssh.AuthMethod
-			if shssh.KeyPath.ValueStringPointer() == nil || *shssh.KeyPath.ValueStringPointer() == "" {
-				if shssh.KeyContent.ValueString() != "" {
-					var err error
-					authMethods, err = k0s_rig.ParseSSHPrivateKey([]byte(shssh.KeyContent.ValueString()), k0s_rig.DefaultPasswordCallback)
-					if err != nil {
-						d.AddError("Passed private key can not be parsed for the host", err.Error())
-						authMethods = []ssh.AuthMethod{}
-					}
-				} else {
-					d.AddError("Both key_path and key_content arguments are not provided.", "Provide either key_path or key_content for the host")
-					authMethods = []ssh.AuthMethod{}
-				}
+			shsshp := shp.AtName("ssh").AtListIndex(0)
+
+			authMethods, ds := k0sctlSSHAuthMethods(shssh.KeyPath, shssh.KeyContent, shssh.KeyPassphrase, shssh.UseAgent, shsshp)
+			d.Append(ds...)
+
+			hostKey, ds := k0sctlSSHHostKey(shssh.HostKey, shsshp)
+			d.Append(ds...)
+
+			h.Connection = k0s_rig.Connection{
+				SSH: &k0s_rig.SSH{
+					Address:          shssh.Address.ValueString(),
+					KeyPath:          shssh.KeyPath.ValueStringPointer(),
+					HostKey:          hostKey,
+					AuthMethods:      authMethods,
+					PasswordCallback: k0sctlSSHPasswordCallback(shssh.KeyPassphrase),
+					User:             shssh.User.ValueString(),
+					Port:             int(shssh.Port.ValueInt64()),
+				},
 			}
-			if len(shssh.Bastion) > 0 {
-				var bastianAuthMethods []ssh.AuthMethod
-				if shssh.Bastion[0].KeyPath.ValueStringPointer() == nil || *shssh.Bastion[0].KeyPath.ValueStringPointer() == "" {
-					if shssh.Bastion[0].KeyContent.ValueString() != "" {
-						var err error
-						bastianAuthMethods, err = k0s_rig.ParseSSHPrivateKey([]byte(shssh.Bastion[0].KeyContent.ValueString()), k0s_rig.DefaultPasswordCallback)
-						if err != nil {
-							d.AddError("Passed private key can not be parsed for the bastian host", err.Error())
-							bastianAuthMethods = []ssh.AuthMethod{}
-						}
-					} else {
-						d.AddError("Both key_path and key_content arguments are not provided.", "Provide either key_path or key_content for the bastian host")
-						bastianAuthMethods = []ssh.AuthMethod{}
-					}
-				}
-				h.Connection = k0s_rig.Connection{
-					SSH: &k0s_rig.SSH{
-						Address:     shssh.Address.ValueString(),
-						KeyPath:     shssh.KeyPath.ValueStringPointer(),
-						AuthMethods: authMethods,
-						User:        shssh.User.ValueString(),
-						Port:        int(shssh.Port.ValueInt64()),
-						Bastion: &k0s_rig.SSH{
-							Address:     shssh.Bastion[0].Address.ValueString(),
-							KeyPath:     shssh.Bastion[0].KeyPath.ValueStringPointer(),
-							AuthMethods: bastianAuthMethods,
-							User:        shssh.Bastion[0].User.ValueString(),
-							Port:        int(shssh.Bastion[0].Port.ValueInt64()),
-						},
-					},
-				}
-			} else {
-				h.Connection = k0s_rig.Connection{
-					SSH: &k0s_rig.SSH{
-						Address:     shssh.Address.ValueString(),
-						KeyPath:     shssh.KeyPath.ValueStringPointer(),
-						AuthMethods: authMethods,
-						User:        shssh.User.ValueString(),
-						Port:        int(shssh.Port.ValueInt64()),
-					},
-				}
 
+			bastion, ds := k0sctlSSHBastions(shssh.Bastion, shsshp)
+			d.Append(ds...)
+			h.SSH.Bastion = bastion
+
+		} else if len(sh.OpenSSH) > 0 {
+			shossh := sh.OpenSSH[0]
+
+			h.Connection = k0s_rig.Connection{
+				OpenSSH: &k0s_rig.OpenSSH{
+					Address:             shossh.Address.ValueString(),
+					User:                shossh.User.ValueStringPointer(),
+					KeyPath:             shossh.KeyPath.ValueStringPointer(),
+					ConfigPath:          shossh.ConfigPath.ValueStringPointer(),
+					DisableMultiplexing: shossh.DisableMultiplexing.ValueBool(),
+				},
+			}
+
+			if !shossh.Port.IsNull() && !shossh.Port.IsUnknown() {
+				p := int(shossh.Port.ValueInt64())
+				h.OpenSSH.Port = &p
 			}
 
+			if !shossh.Options.IsNull() && !shossh.Options.IsUnknown() {
+				var shosshos map[string]string
+				d.Append(shossh.Options.ElementsAs(ctx, &shosshos, false)...)
+
+				h.OpenSSH.Options = k0s_rig.OpenSSHOptions{}
+				for k, v := range shosshos {
+					h.OpenSSH.Options[k] = v
+				}
+			}
+		} else if len(sh.Localhost) > 0 {
+			h.Connection = k0s_rig.Connection{
+				Localhost: &k0s_rig.Localhost{
+					Enabled: true,
+				},
+			}
 		} else if len(sh.WinRM) > 0 {
 			shwinrm := sh.WinRM[0]
+			shwinrmp := shp.AtName("winrm").AtListIndex(0)
+
+			bastion, ds := k0sctlSSHBastions(shwinrm.Bastion, shwinrmp)
+			d.Append(ds...)
 
 			h.Connection = k0s_rig.Connection{
 				WinRM: &k0s_rig.WinRM{
-					Address:  shwinrm.Address.ValueString(),
-					Password: shwinrm.Password.ValueString(),
-					User:     shwinrm.User.ValueString(),
-					Port:     int(shwinrm.Port.ValueInt64()),
-					UseHTTPS: shwinrm.UseHTTPS.ValueBool(),
-					Insecure: shwinrm.Insecure.ValueBool(),
+					Address:       shwinrm.Address.ValueString(),
+					Password:      shwinrm.Password.ValueString(),
+					User:          shwinrm.User.ValueString(),
+					Port:          int(shwinrm.Port.ValueInt64()),
+					UseHTTPS:      shwinrm.UseHTTPS.ValueBool(),
+					Insecure:      shwinrm.Insecure.ValueBool(),
+					UseNTLM:       shwinrm.UseNTLM.ValueBool(),
+					CACertPath:    shwinrm.CACertPath.ValueString(),
+					CertPath:      shwinrm.CertPath.ValueString(),
+					KeyPath:       shwinrm.KeyPath.ValueString(),
+					TLSServerName: shwinrm.TLSServerName.ValueString(),
+					Bastion:       bastion,
 				},
 			}
+
+			// k0s only supports windows workers, and winrm is only used for windows hosts
+			if !sh.Role.IsUnknown() && sh.Role.ValueString() != "worker" {
+				d.AddAttributeError(shp.AtName("role"), "Windows hosts can only be workers", fmt.Sprintf("The host %s is connected to with winrm, so it is a windows host, which k0s only supports with the worker role, not %q.", shwinrm.Address.ValueString(), sh.Role.ValueString()))
+			}
 		}
 
 		if len(sh.Hooks) > 0 {
-			shh := sh.Hooks[0]
+			for action, shas := range sh.Hooks[0].Actions() {
+				if len(shas) == 0 {
+					continue
+				}
 
-			if len(shh.Apply) > 0 {
-				ha := shh.Apply[0]
+				hha := map[string][]string{}
+				for stage, shs := range map[string]types.List{
+					"before": shas[0].Before,
+					"after":  shas[0].After,
+				} {
+					if shs.IsUnknown() {
+						// the hooks are not known until apply
+						continue
+					}
 
-				hha := map[string][]string{
-					"before": {},
-					"after":  {},
-				}
-				var shab []string
-				if diag := ha.Before.ElementsAs(context.Background(), &shab, true); diag == nil {
-					hha["before"] = shab
-				}
-				var shaa []string
-				if diag := ha.After.ElementsAs(context.Background(), &shaa, true); diag == nil {
-					hha["after"] = shab
+					var shss []string
+					d.Append(shs.ElementsAs(ctx, &shss, true)...)
+					if len(shss) > 0 {
+						hha[stage] = shss
+					}
 				}
 
-				h.Hooks["apply"] = hha
+				if len(hha) > 0 {
+					h.Hooks[action] = hha
+				}
 			}
 		}
 
 		c.Spec.Hosts = append(c.Spec.Hosts, &h)
 	}
 
-	// add the cluster yaml to the model
-	if kyb, err := yaml.Marshal(c); err != nil {
+	// add the cluster yaml to the model, k0s_yaml is not sensitive so the secrets of the hosts are redacted
+	if kyb, err := k0sctlRedactedYaml(c); err != nil {
 		d.AddWarning("failed to marshall the k0sctl config to yaml", err.Error())
 	} else {
 		ksm.K0sYaml = types.StringValue(string(kyb))
@@ -544,79 +1123,798 @@ func (ksm *k0sctlSchemaModel) Cluster(ctx context.Context) (k0sctl_v1beta1.Clust
 	return c, d
 }
 
-// AddKubeconfig read bytes for a kube config file, and interpret it into parametrized config values.
-func (ksm *k0sctlSchemaModel) AddKubeconfig(r io.Reader) diag.Diagnostics {
+// k0sctlRedactedSecrets the paths of the k0sctl config yaml values which hold secrets, "*" matching any key or index.
+var k0sctlRedactedSecrets = [][]string{
+	{"spec", "hosts", "*", "winRM", "password"},
+	{"spec", "hosts", "*", "environment", "*"},
+	{"spec", "hosts", "*", "files", "*", "data"},
+}
+
+// k0sctlRedactedYaml the k0sctl config yaml, with the secrets of the hosts replaced by a placeholder.
+func k0sctlRedactedYaml(c k0sctl_v1beta1.Cluster) ([]byte, error) {
+	kyb, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// a map slice keeps the order of the keys
+	var ky yaml.MapSlice
+	if err := yaml.Unmarshal(kyb, &ky); err != nil {
+		return nil, err
+	}
+
+	for _, p := range k0sctlRedactedSecrets {
+		k0sctlRedact(ky, p)
+	}
+
+	return yaml.Marshal(ky)
+}
+
+// k0sctlRedact replace the non-empty values at a path of a yaml value.
+func k0sctlRedact(v interface{}, p []string) interface{} {
+	if len(p) == 0 {
+		if s, ok := v.(string); ok && s == "" {
+			return v
+		}
+		return "(sensitive value)"
+	}
+
+	switch vv := v.(type) {
+	case yaml.MapSlice:
+		for i, mi := range vv {
+			if k, ok := mi.Key.(string); ok && (p[0] == "*" || p[0] == k) {
+				vv[i].Value = k0sctlRedact(mi.Value, p[1:])
+			}
+		}
+	case []interface{}:
+		if p[0] == "*" {
+			for i := range vv {
+				vv[i] = k0sctlRedact(vv[i], p[1:])
+			}
+		}
+	}
+
+	return v
+}
+
+// k0sctlSSHAuthMethods build the ssh auth methods of a host or bastion from its key path, key content and agent options.
+//
+// A key_path is not read here, but when connecting, using the passphrase if the key is encrypted.
+func k0sctlSSHAuthMethods(keyPath, keyContent, keyPassphrase types.String, useAgent types.Bool, p path.Path) ([]ssh.AuthMethod, diag.Diagnostics) {
 	d := diag.Diagnostics{}
-	k8bytes, _ := io.ReadAll(r)
 
-	// Struct representation of a kube config file.
-	// see https://zhwt.github.io/yaml-to-go/
-	var cbkHolder struct {
-		APIVersion  string            `yaml:"apiVersion"`
-		Kind        string            `yaml:"kind"`
-		Preferences map[string]string `yaml:"preferences"`
-		Clusters    []struct {
-			Name    string `yaml:"name"`
-			Cluster struct {
-				CertificateAuthorityData string `yaml:"certificate-authority-data"`
-				Server                   string `yaml:"server"`
-			} `yaml:"cluster"`
-		} `yaml:"clusters"`
-		Contexts []struct {
-			Name    string `yaml:"name"`
-			Context struct {
-				Cluster string `yaml:"cluster"`
-				User    string `yaml:"user"`
-			} `yaml:"context"`
-		} `yaml:"contexts"`
-		CurrentContext string `yaml:"current-context"`
-		Users          []struct {
-			Name string `yaml:"name"`
-			User struct {
-				ClientCertificateData string `yaml:"client-certificate-data"`
-				ClientKeyData         string `yaml:"client-key-data"`
-			} `yaml:"user"`
-		} `yaml:"users"`
+	if keyPath.IsUnknown() || keyContent.IsUnknown() || keyPassphrase.IsUnknown() {
+		// the key is not known until apply, so it can't be validated yet
+		return nil, d
+	}
+
+	var signers []ssh.Signer
+
+	if keyPath.ValueString() == "" {
+		if keyContent.ValueString() != "" {
+			s, err := k0sctlSSHParsePrivateKey([]byte(keyContent.ValueString()), keyPassphrase)
+			if err != nil {
+				d.AddAttributeError(p.AtName("key_content"), "Passed private key can not be parsed", err.Error())
+			} else {
+				signers = append(signers, s)
+			}
+		} else if !useAgent.ValueBool() {
+			d.AddAttributeError(p.AtName("key_path"), "Both key_path and key_content arguments are not provided.", "Provide either key_path or key_content, or set use_agent to authenticate with the keys of the ssh agent")
+		}
+	}
+
+	// the keys are offered in a single auth method, as the ssh client only tries the first public key method. It is
+	// always set, as rig offers all of the keys of the ssh agent when there is none, even without use_agent.
+	return []ssh.AuthMethod{ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+		ss := append([]ssh.Signer{}, signers...)
+
+		// the key file and the agent are only read when connecting, so that planning does not depend on them
+		if kp := keyPath.ValueString(); kp != "" {
+			s, err := k0sctlSSHReadPrivateKey(kp, keyPassphrase)
+			if err != nil {
+				return nil, err
+			}
+			ss = append(ss, s)
+		}
+
+		if useAgent.ValueBool() {
+			a, err := k0s_rig_agent.NewClient()
+			if err != nil {
+				return nil, fmt.Errorf("could not connect to the ssh agent: %w", err)
+			}
+			as, err := a.Signers()
+			if err != nil {
+				return nil, fmt.Errorf("could not list the keys of the ssh agent: %w", err)
+			}
+			ss = append(ss, as...)
+		}
+
+		return ss, nil
+	})}, d
+}
+
+// k0sctlSSHReadPrivateKey read and parse the private key file at keyPath, which may start with ~ for the home directory.
+func k0sctlSSHReadPrivateKey(keyPath string, keyPassphrase types.String) (ssh.Signer, error) {
+	if rest, ok := strings.CutPrefix(keyPath, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("could not expand the ssh key path %s: %w", keyPath, err)
+		}
+		keyPath = filepath.Join(home, rest)
+	}
+
+	kb, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the ssh key: %w", err)
+	}
+
+	s, err := k0sctlSSHParsePrivateKey(kb, keyPassphrase)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the ssh key %s: %w", keyPath, err)
+	}
+
+	return s, nil
+}
+
+// k0sctlSSHParsePrivateKey parse a private key, decrypted with the passphrase if it is encrypted.
+func k0sctlSSHParsePrivateKey(kb []byte, keyPassphrase types.String) (ssh.Signer, error) {
+	s, err := ssh.ParsePrivateKey(kb)
+
+	var pme *ssh.PassphraseMissingError
+	if !errors.As(err, &pme) {
+		return s, err
+	}
+
+	pp, err := k0sctlSSHPasswordCallback(keyPassphrase)()
+	if err != nil {
+		return nil, err
+	}
+
+	return ssh.ParsePrivateKeyWithPassphrase(kb, []byte(pp))
+}
+
+// k0sctlSSHHostKey the host key to pin the connection to, empty if the known_hosts file is used.
+//
+// An unknown host key is one which has not been observed yet, on the first connection of a trust_on_first_use host.
+func k0sctlSSHHostKey(hostKey types.String, p path.Path) (string, diag.Diagnostics) {
+	d := diag.Diagnostics{}
+
+	if hostKey.IsNull() || hostKey.IsUnknown() {
+		return "", d
+	}
+
+	hk, err := k0sctlHostKeyNormalize(hostKey.ValueString())
+	if err != nil {
+		d.AddAttributeError(p.AtName("host_key"), "Could not interpret the host key", err.Error())
+	}
+
+	return hk, d
+}
+
+// k0sctlSSHBastions the rig bastion chain for a list of bastions, which rig connects to from the last one.
+func k0sctlSSHBastions(bastions []k0sctlSchemaModelSpecHostSSHBastion, p path.Path) (*k0s_rig.SSH, diag.Diagnostics) {
+	d := diag.Diagnostics{}
+
+	var bastion *k0s_rig.SSH
+
+	// each bastion is connected to through the previous one
+	for j, shb := range bastions {
+		shbp := p.AtName("bastion").AtListIndex(j)
+
+		authMethods, ds := k0sctlSSHAuthMethods(shb.KeyPath, shb.KeyContent, shb.KeyPassphrase, shb.UseAgent, shbp)
+		d.Append(ds...)
+
+		hostKey, ds := k0sctlSSHHostKey(shb.HostKey, shbp)
+		d.Append(ds...)
+
+		bastion = &k0s_rig.SSH{
+			Address:          shb.Address.ValueString(),
+			KeyPath:          shb.KeyPath.ValueStringPointer(),
+			HostKey:          hostKey,
+			AuthMethods:      authMethods,
+			PasswordCallback: k0sctlSSHPasswordCallback(shb.KeyPassphrase),
+			User:             shb.User.ValueString(),
+			Port:             int(shb.Port.ValueInt64()),
+			Bastion:          bastion,
+		}
+	}
+
+	return bastion, d
+}
+
+// k0sctlSchemaModelSSHBastions the list of bastions for a rig bastion chain, from the first one connected to.
+func k0sctlSchemaModelSSHBastions(bastion *k0s_rig.SSH) []k0sctlSchemaModelSpecHostSSHBastion {
+	var shbs []k0sctlSchemaModelSpecHostSSHBastion
+
+	for b := bastion; b != nil; b = b.Bastion {
+		shbs = append([]k0sctlSchemaModelSpecHostSSHBastion{
+			{
+				Address:         types.StringValue(b.Address),
+				KeyPath:         types.StringPointerValue(b.KeyPath),
+				KeyContent:      types.StringNull(),
+				KeyPassphrase:   types.StringNull(),
+				UseAgent:        types.BoolNull(),
+				HostKey:         helperStringValueOrNull(b.HostKey),
+				TrustOnFirstUse: types.BoolNull(),
+				User:            types.StringValue(b.User),
+				Port:            types.Int64Value(int64(b.Port)),
+			},
+		}, shbs...)
+	}
+
+	return shbs
+}
+
+// k0sctlSSHPasswordCallback a non-interactive callback for decrypting a private key, with the key_passphrase.
+//
+// The rig default callback prompts on the terminal, which would hang the provider.
+func k0sctlSSHPasswordCallback(keyPassphrase types.String) k0s_rig.PasswordCallback {
+	return func() (string, error) {
+		if keyPassphrase.ValueString() == "" {
+			return "", fmt.Errorf("the private key is encrypted, provide its key_passphrase")
+		}
+		return keyPassphrase.ValueString(), nil
 	}
+}
+
+// k0sctlClusterFromYaml interpret k0sctl.yaml file content into a validated k0sctl cluster configuration struct.
+func k0sctlClusterFromYaml(kyb []byte) (k0sctl_v1beta1.Cluster, diag.Diagnostics) {
+	var c k0sctl_v1beta1.Cluster
+	d := diag.Diagnostics{}
+
+	if err := yaml.Unmarshal(kyb, &c); err != nil {
+		d.AddError("Could not interpret the k0sctl config", err.Error())
+		return c, d
+	}
+
+	if err := c.Validate(); err != nil {
+		d.AddError("k0sctl cluster validation failed", err.Error())
+	}
+
+	return c, d
+}
+
+// UploadFile build a k0sctl upload file from the model data.
+//
+// The file is passed through the k0sctl yaml unmarshalling, which resolves the local sources to upload.
+func (shf k0sctlSchemaModelSpecHostFile) UploadFile() (*k0sctl_v1beta1_cluster.UploadFile, error) {
+	fm := map[string]string{}
+
+	for k, v := range map[string]types.String{
+		"name":    shf.Name,
+		"src":     shf.Src,
+		"data":    shf.Content,
+		"dst":     shf.Dst,
+		"dstDir":  shf.DstDir,
+		"perm":    shf.Perm,
+		"dirPerm": shf.DirPerm,
+		"user":    shf.User,
+		"group":   shf.Group,
+	} {
+		if v.ValueString() != "" {
+			fm[k] = v.ValueString()
+		}
+	}
+
+	fb, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, err
+	}
+
+	var uf k0sctl_v1beta1_cluster.UploadFile
+	if err := yaml.Unmarshal(fb, &uf); err != nil {
+		return nil, err
+	}
+
+	return &uf, nil
+}
+
+// k0sctlPermString a k0sctl file permission mode as a string, it may have been written as a string or a number.
+func k0sctlPermString(pm interface{}) string {
+	switch v := pm.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return fmt.Sprintf("%04o", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// k0sctlRemovedHosts find the prior hosts which are not in the current hosts, and mark them to be reset.
+//
+// Hosts are matched on their connection address only, so that a host which is connected to differently (e.g. on
+// another port, or through a bastion) is not reset.
+func k0sctlRemovedHosts(prior, current k0sctl_v1beta1_cluster.Hosts) k0sctl_v1beta1_cluster.Hosts {
+	rhs := k0sctl_v1beta1_cluster.Hosts{}
+
+	for _, ph := range prior {
+		found := false
+		for _, h := range current {
+			if h.Address() == ph.Address() {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			ph.Reset = true
+			rhs = append(rhs, ph)
+		}
+	}
+
+	return rhs
+}
+
+// FromCluster populate the model from a k0sctl cluster configuration struct, the reverse of Cluster().
+func (ksm *k0sctlSchemaModel) FromCluster(ctx context.Context, c k0sctl_v1beta1.Cluster) diag.Diagnostics {
+	tflog.Info(ctx, "Creating schema from k0sctl Cluster", map[string]interface{}{})
+
+	d := diag.Diagnostics{}
+
+	ksm.SkipCreate = types.BoolValue(false)
+	ksm.SkipDestroy = types.BoolValue(false)
+	ksm.Force = types.BoolValue(false)
+	ksm.NoWait = types.BoolValue(false)
+	ksm.NoDrain = types.BoolValue(false)
+	ksm.DisableDowngradeCheck = types.BoolValue(false)
+	ksm.KubeSkipTLSVerify = types.BoolValue(false)
+	ksm.KubeconfigAPIAddress = types.StringNull()
+	ksm.RestoreFrom = types.StringNull()
+	ksm.DryRun = types.BoolValue(false)
+	ksm.DryRunReport = types.ListNull(k0sctlDryRunReportType)
+
+	ksm.KubeYaml = types.StringNull()
+	ksm.Kubeconfig = types.ObjectNull(k0sctlKubeconfigType.AttrTypes)
+	ksm.KubeHost = types.StringNull()
+	ksm.CaCert = types.StringNull()
+	ksm.PrivateKey = types.StringNull()
+	ksm.ClientCert = types.StringNull()
+	ksm.K0sYaml = types.StringNull()
+	ksm.Timeouts = timeouts.Value{Object: types.ObjectNull(map[string]attr.Type{
+		"create": types.StringType,
+		"update": types.StringType,
+		"delete": types.StringType,
+	})}
+
+	if c.Metadata != nil {
+		ksm.Metadata.Name = types.StringValue(c.Metadata.Name)
+	}
+	ksm.Id = ksm.Metadata.Name
+
+	if c.Spec == nil {
+		d.AddError("k0sctl config has no spec", "The k0sctl configuration does not contain a spec section")
+		return d
+	}
+
+	ksm.Spec.K0s = k0sctlSchemaModelSpecK0s{
+		Version:         types.StringNull(),
+		Config:          types.DynamicNull(),
+		VersionChannel:  types.StringValue("stable"),
+		VersionEndpoint: types.StringValue(k0sctl_default_version_endpoint),
+		ResolvedVersion: types.StringNull(),
+		DynamicConfig:   types.BoolValue(false),
+
+		ConfigSchemaEndpoint: types.StringValue(k0sctl_default_config_schema_endpoint),
+	}
+
+	if c.Spec.K0s != nil {
+		if c.Spec.K0s.Version != nil {
+			ksm.Spec.K0s.Version = types.StringValue(c.Spec.K0s.Version.String())
+			ksm.Spec.K0s.ResolvedVersion = ksm.Spec.K0s.Version
+		}
+		if c.Spec.K0s.VersionChannel != "" {
+			ksm.Spec.K0s.VersionChannel = types.StringValue(c.Spec.K0s.VersionChannel)
+		}
+		ksm.Spec.K0s.DynamicConfig = types.BoolValue(c.Spec.K0s.DynamicConfig)
+
+		if len(c.Spec.K0s.Config) > 0 {
+			if kcb, err := yaml.Marshal(c.Spec.K0s.Config); err != nil {
+				d.AddError("K0s config marshal failed", err.Error())
+			} else {
+				ksm.Spec.K0s.Config = types.DynamicValue(types.StringValue(string(kcb)))
+			}
+		}
+	}
+
+	ksm.Spec.Hosts = []k0sctlSchemaModelSpecHost{}
+
+	// ssh hosts which authenticate with something k0sctl.yaml can't describe
+	sshAddresses := []string{}
+
+	for _, h := range c.Spec.Hosts {
+		sh := k0sctlSchemaModelSpecHost{
+			Role:           types.StringValue(h.Role),
+			PrivateAddress: types.StringNull(),
+			Hostname:       types.StringNull(),
+			NoTaints:       types.BoolNull(),
+			Environment:    types.MapNull(types.StringType),
+			UploadBinary:   types.BoolNull(),
+			K0sBinaryPath:  helperStringValueOrNull(h.K0sBinaryPath),
+			K0sDownloadURL: helperStringValueOrNull(h.K0sDownloadURL),
+		}
+
+		if h.PrivateAddress != "" {
+			sh.PrivateAddress = types.StringValue(h.PrivateAddress)
+		}
+		if h.HostnameOverride != "" {
+			sh.Hostname = types.StringValue(h.HostnameOverride)
+		}
+		if h.NoTaints {
+			sh.NoTaints = types.BoolValue(true)
+		}
+		if h.UploadBinary {
+			sh.UploadBinary = types.BoolValue(true)
+		}
+		if len(h.Environment) > 0 {
+			mv, ds := types.MapValueFrom(ctx, types.StringType, h.Environment)
+			d.Append(ds...)
+			sh.Environment = mv
+		}
+
+		for _, hif := range h.InstallFlags {
+			sh.InstallFlags = append(sh.InstallFlags, types.StringValue(hif))
+		}
+
+		for _, hf := range h.Files {
+			sh.Files = append(sh.Files, k0sctlSchemaModelSpecHostFile{
+				Name:    helperStringValueOrNull(hf.Name),
+				Src:     helperStringValueOrNull(hf.Source),
+				Content: helperStringValueOrNull(hf.Data),
+				Dst:     helperStringValueOrNull(hf.DestinationFile),
+				DstDir:  helperStringValueOrNull(hf.DestinationDir),
+				Perm:    helperStringValueOrNull(k0sctlPermString(hf.PermMode)),
+				DirPerm: helperStringValueOrNull(k0sctlPermString(hf.DirPermMode)),
+				User:    helperStringValueOrNull(hf.User),
+				Group:   helperStringValueOrNull(hf.Group),
+			})
+		}
+
+		if h.SSH != nil {
+			shssh := k0sctlSchemaModelSpecHostSSH{
+				Address:         types.StringValue(h.SSH.Address),
+				KeyPath:         types.StringPointerValue(h.SSH.KeyPath),
+				KeyContent:      types.StringNull(),
+				KeyPassphrase:   types.StringNull(),
+				UseAgent:        types.BoolNull(),
+				HostKey:         helperStringValueOrNull(h.SSH.HostKey),
+				TrustOnFirstUse: types.BoolNull(),
+				User:            types.StringValue(h.SSH.User),
+				Port:            types.Int64Value(int64(h.SSH.Port)),
+			}
+
+			shssh.Bastion = k0sctlSchemaModelSSHBastions(h.SSH.Bastion)
+
+			sh.SSH = []k0sctlSchemaModelSpecHostSSH{shssh}
+			sshAddresses = append(sshAddresses, h.SSH.Address)
+		} else if h.WinRM != nil {
+			sh.WinRM = []k0sctlSchemaModelSpecHostWinrm{
+				{
+					Address:       types.StringValue(h.WinRM.Address),
+					User:          types.StringValue(h.WinRM.User),
+					Password:      helperStringValueOrNull(h.WinRM.Password),
+					Port:          types.Int64Value(int64(h.WinRM.Port)),
+					UseHTTPS:      types.BoolValue(h.WinRM.UseHTTPS),
+					Insecure:      types.BoolValue(h.WinRM.Insecure),
+					UseNTLM:       types.BoolValue(h.WinRM.UseNTLM),
+					CACertPath:    helperStringValueOrNull(h.WinRM.CACertPath),
+					CertPath:      helperStringValueOrNull(h.WinRM.CertPath),
+					KeyPath:       helperStringValueOrNull(h.WinRM.KeyPath),
+					TLSServerName: helperStringValueOrNull(h.WinRM.TLSServerName),
+					Bastion:       k0sctlSchemaModelSSHBastions(h.WinRM.Bastion),
+				},
+			}
+		} else if h.OpenSSH != nil {
+			shossh := k0sctlSchemaModelSpecHostOpenSSH{
+				Address:             types.StringValue(h.OpenSSH.Address),
+				User:                types.StringPointerValue(h.OpenSSH.User),
+				Port:                types.Int64Null(),
+				KeyPath:             types.StringPointerValue(h.OpenSSH.KeyPath),
+				ConfigPath:          types.StringPointerValue(h.OpenSSH.ConfigPath),
+				Options:             types.MapNull(types.StringType),
+				DisableMultiplexing: types.BoolNull(),
+			}
+
+			if h.OpenSSH.Port != nil {
+				shossh.Port = types.Int64Value(int64(*h.OpenSSH.Port))
+			}
+			if h.OpenSSH.DisableMultiplexing {
+				shossh.DisableMultiplexing = types.BoolValue(true)
+			}
+			if len(h.OpenSSH.Options) > 0 {
+				hos := map[string]string{}
+				for k, v := range h.OpenSSH.Options {
+					hos[k] = fmt.Sprint(v)
+				}
+				mv, ds := types.MapValueFrom(ctx, types.StringType, hos)
+				d.Append(ds...)
+				shossh.Options = mv
+			}
+
+			sh.OpenSSH = []k0sctlSchemaModelSpecHostOpenSSH{shossh}
+		} else if h.Localhost != nil {
+			sh.Localhost = []k0sctlSchemaModelSpecHostLocalhost{{}}
+		} else {
+			d.AddError("Unsupported host connection", fmt.Sprintf("%s: the host has no ssh, winrm, openssh or localhost connection", h))
+		}
+
+		if len(h.Hooks) > 0 {
+			shh := k0sctlSchemaModelSpecHostHooks{}
+
+			for action, hha := range h.Hooks {
+				sha := k0sctlSchemaModelSpecHostHookAction{
+					Before: types.ListNull(types.StringType),
+					After:  types.ListNull(types.StringType),
+				}
+
+				if hb, ok := hha["before"]; ok {
+					lv, ds := types.ListValueFrom(ctx, types.StringType, hb)
+					d.Append(ds...)
+					sha.Before = lv
+				}
+				if ha, ok := hha["after"]; ok {
+					lv, ds := types.ListValueFrom(ctx, types.StringType, ha)
+					d.Append(ds...)
+					sha.After = lv
+				}
+
+				switch action {
+				case "apply":
+					shh.Apply = []k0sctlSchemaModelSpecHostHookAction{sha}
+				case "reset":
+					shh.Reset = []k0sctlSchemaModelSpecHostHookAction{sha}
+				case "backup":
+					shh.Backup = []k0sctlSchemaModelSpecHostHookAction{sha}
+				default:
+					d.AddWarning("Unsupported host hooks", fmt.Sprintf("%s: hooks for the k0sctl %s action are not supported and were ignored", h, action))
+				}
+			}
+
+			sh.Hooks = []k0sctlSchemaModelSpecHostHooks{shh}
+		}
+
+		ksm.Spec.Hosts = append(ksm.Spec.Hosts, sh)
+	}
+
+	if len(sshAddresses) > 0 {
+		d.AddWarning(
+			"SSH authentication was not imported",
+			fmt.Sprintf("k0sctl.yaml only describes the key_path of ssh hosts and bastions, their key_content, key_passphrase and use_agent are not imported. Hosts which k0sctl authenticated with the ssh agent or the default keys need key_path, key_content or use_agent set in the configuration: %s", strings.Join(sshAddresses, ", ")),
+		)
+	}
+
+	return d
+}
+
+// AddDryRunReport interpret the host operations of a dry run apply into the dry run report.
+func (ksm *k0sctlSchemaModel) AddDryRunReport(ctx context.Context, hos []provider_action.HostOperation) diag.Diagnostics {
+	drrs := make([]k0sctlSchemaModelDryRunReport, len(hos))
+
+	for i, ho := range hos {
+		drrs[i] = k0sctlSchemaModelDryRunReport{
+			Host:           types.StringValue(ho.Host),
+			Role:           types.StringValue(ho.Role),
+			Operation:      types.StringValue(ho.Operation),
+			RunningVersion: types.StringValue(ho.RunningVersion),
+		}
+	}
+
+	lv, d := types.ListValueFrom(ctx, k0sctlDryRunReportType, drrs)
+	ksm.DryRunReport = lv
+
+	return d
+}
+
+// AddKubeconfig read bytes for a kube config file, and interpret it into parametrized config values.
+func (ksm *k0sctlSchemaModel) AddKubeconfig(ctx context.Context, r io.Reader) diag.Diagnostics {
+	k8bytes, _ := io.ReadAll(r)
 
 	ksm.KubeYaml = types.StringValue(string(k8bytes))
 
-	if err := yaml.UnmarshalStrict(k8bytes, &cbkHolder); err != nil {
-		d.AddError("Error interpreting k8s context from k0sctl response", err.Error())
+	kc, d := k0sctlKubeconfigParse(k8bytes)
+	if d.HasError() {
 		return d
 	}
 
-	var contextName, clusterName, userName string
+	kcc := kc.Current()
+	ksm.KubeHost = kcc.Host
+	ksm.CaCert = kcc.CaCert
+	ksm.PrivateKey = kcc.PrivateKey
+	ksm.ClientCert = kcc.ClientCert
+
+	ov, ds := kc.ObjectValue(ctx)
+	d.Append(ds...)
+	ksm.Kubeconfig = ov
+
+	return d
+}
+
+// AddHostKeys settle the host keys which are still unknown after apply. The host keys of trust_on_first_use hosts
+// are recorded by CheckHostKeys when connecting, the ones which were not connected to are left empty.
+func (ksm *k0sctlSchemaModel) AddHostKeys(ctx context.Context) diag.Diagnostics {
+	d := diag.Diagnostics{}
+
+	nullBastionHostKeys := func(bastions []k0sctlSchemaModelSpecHostSSHBastion) {
+		for j := range bastions {
+			if shb := &bastions[j]; shb.HostKey.IsUnknown() {
+				shb.HostKey = types.StringNull()
+			}
+		}
+	}
+
+	for i, sh := range ksm.Spec.Hosts {
+		if len(sh.SSH) > 0 {
+			shssh := &ksm.Spec.Hosts[i].SSH[0]
+
+			if shssh.HostKey.IsUnknown() {
+				shssh.HostKey = types.StringNull()
+			}
+
+			nullBastionHostKeys(shssh.Bastion)
+		} else if len(sh.WinRM) > 0 {
+			nullBastionHostKeys(sh.WinRM[0].Bastion)
+		}
+	}
+
+	return d
+}
+
+// AddK0sStates update the model with the k0s state discovered on the hosts, so that drift shows up in the next plan.
+//
+// A host that no longer runs k0s, or that can't be reached, is removed from the model, so that terraform plans to
+// install it again. A host running a different role gets the running role, and when the controllers run a different
+// k0s version, the version gets the lowest one, so that terraform plans an update back to the configured values.
+//
+// The states are matched to the hosts of the model on their connection address, as k0sctlRemovedHosts does.
+func (ksm *k0sctlSchemaModel) AddK0sStates(ctx context.Context, hosts k0sctl_v1beta1_cluster.Hosts, states map[*k0sctl_v1beta1_cluster.Host]provider_phase.K0sHostState) diag.Diagnostics {
+	d := diag.Diagnostics{}
 
-	contextName = cbkHolder.CurrentContext
+	type hostState struct {
+		host  *k0sctl_v1beta1_cluster.Host
+		state provider_phase.K0sHostState
+	}
 
-	for _, context := range cbkHolder.Contexts {
-		if context.Name == contextName {
-			clusterName = context.Context.Cluster
-			userName = context.Context.User
-			break
+	ahss := map[string]hostState{}
+	for _, h := range hosts {
+		if s, ok := states[h]; ok {
+			ahss[h.Address()] = hostState{host: h, state: s}
 		}
 	}
 
-	for _, cluster := range cbkHolder.Clusters {
-		if cluster.Name == clusterName {
-			ksm.KubeHost = types.StringValue(cluster.Cluster.Server)
-			ksm.CaCert = types.StringValue(helperStringBase64Decode(cluster.Cluster.CertificateAuthorityData))
-			break
+	var controllerVersion, workerVersion *k0sversion.Version
+	runningVersions := map[string][]string{}
+
+	shs := []k0sctlSchemaModelSpecHost{}
+
+	for i, sh := range ksm.Spec.Hosts {
+		hs, ok := ahss[sh.Address()]
+		if !ok {
+			shs = append(shs, sh)
+			continue
+		}
+
+		h, s := hs.host, hs.state
+
+		if s.Unreachable != nil {
+			d.AddAttributeWarning(
+				path.Root("spec").AtName("host").AtListIndex(i),
+				"Host unreachable",
+				fmt.Sprintf("The host %s could not be connected to, so its k0s state is unknown. It is considered missing, so that the next apply installs it again: %s", h, s.Unreachable),
+			)
+			continue
+		}
+
+		if !s.Running {
+			d.AddAttributeWarning(
+				path.Root("spec").AtName("host").AtListIndex(i),
+				"k0s is not running",
+				fmt.Sprintf("k0s is not installed or not running on the host %s. It is considered missing, so that the next apply installs it again.", h),
+			)
+			continue
+		}
+
+		if s.Role != "" && s.Role != sh.Role.ValueString() {
+			tflog.Warn(ctx, "k0s is running with a different role on host", map[string]interface{}{"host": h.String(), "role": s.Role})
+			sh.Role = types.StringValue(s.Role)
+		}
+
+		if s.Version != nil {
+			runningVersions[s.Version.String()] = append(runningVersions[s.Version.String()], h.String())
+
+			if s.Role == "worker" {
+				if workerVersion == nil || s.Version.LessThan(workerVersion) {
+					workerVersion = s.Version
+				}
+			} else if controllerVersion == nil || s.Version.LessThan(controllerVersion) {
+				controllerVersion = s.Version
+			}
 		}
+
+		shs = append(shs, sh)
 	}
 
-	for _, user := range cbkHolder.Users {
-		if user.Name == userName {
-			ksm.PrivateKey = types.StringValue(helperStringBase64Decode(user.User.ClientKeyData))
-			ksm.ClientCert = types.StringValue(helperStringBase64Decode(user.User.ClientCertificateData))
-			break
+	ksm.Spec.Hosts = shs
+
+	if len(runningVersions) > 1 {
+		rvs := []string{}
+		for v, hs := range runningVersions {
+			rvs = append(rvs, fmt.Sprintf("%s on %s", v, strings.Join(hs, ", ")))
 		}
+		sort.Strings(rvs)
+
+		d.AddAttributeWarning(
+			path.Root("spec").AtName("k0s"),
+			"Mixed k0s versions",
+			fmt.Sprintf("The hosts run different k0s versions, the lowest version of the controllers is considered the running one:\n\n%s", strings.Join(rvs, "\n")),
+		)
+	}
+
+	// the workers are only considered if no controller could be read
+	rv := controllerVersion
+	if rv == nil {
+		rv = workerVersion
+	}
+
+	// without a version, drift is recorded in the resolved version
+	kv := &ksm.Spec.K0s.Version
+	if kv.IsNull() {
+		kv = &ksm.Spec.K0s.ResolvedVersion
+	}
+
+	if cv, err := k0sversion.NewVersion(kv.ValueString()); rv != nil && (err != nil || !rv.Equal(cv)) {
+		tflog.Warn(ctx, "k0s is running a different version", map[string]interface{}{"version": rv.String()})
+		*kv = types.StringValue(rv.String())
 	}
 
 	return d
 }
 
+// Address the connection address of a host, as rig reports it for the connection.
+func (sh k0sctlSchemaModelSpecHost) Address() string {
+	switch {
+	case len(sh.SSH) > 0:
+		return sh.SSH[0].Address.ValueString()
+	case len(sh.WinRM) > 0:
+		return sh.WinRM[0].Address.ValueString()
+	case len(sh.OpenSSH) > 0:
+		return sh.OpenSSH[0].Address.ValueString()
+	case len(sh.Localhost) > 0:
+		return (&k0s_rig.Localhost{}).IPAddress()
+	}
+	return ""
+}
+
+// AddResolvedVersion record the installed k0s version, if it was not resolved when planning.
+func (ksm *k0sctlSchemaModel) AddResolvedVersion(c k0sctl_v1beta1.Cluster) {
+	if !ksm.Spec.K0s.ResolvedVersion.IsUnknown() {
+		return
+	}
+
+	if c.Spec != nil && c.Spec.K0s != nil && c.Spec.K0s.Version != nil {
+		ksm.Spec.K0s.ResolvedVersion = types.StringValue(c.Spec.K0s.Version.String())
+	} else {
+		ksm.Spec.K0s.ResolvedVersion = types.StringNull()
+	}
+}
+
+var k0sctlDryRunReportType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"host":            types.StringType,
+		"role":            types.StringType,
+		"operation":       types.StringType,
+		"running_version": types.StringType,
+	},
+}
+
+type k0sctlSchemaModelDryRunReport struct {
+	Host           types.String `tfsdk:"host"`
+	Role           types.String `tfsdk:"role"`
+	Operation      types.String `tfsdk:"operation"`
+	RunningVersion types.String `tfsdk:"running_version"`
+}
+
 type k0sctlSchemaClusterMetadata struct {
 	Name types.String `tfsdk:"name"`
 }
@@ -626,50 +1924,148 @@ type k0sctlSchemaModelSpec struct {
 	K0s   k0sctlSchemaModelSpecK0s    `tfsdk:"k0s"`
 }
 
+// k0sctlSchemaModelHostsSpec the spec of the resources which connect to an existing cluster.
+type k0sctlSchemaModelHostsSpec struct {
+	Hosts []k0sctlSchemaModelSpecHost `tfsdk:"host"`
+}
+
+// Cluster build a k0sctl cluster configuration struct of the hosts, to connect to an existing cluster.
+//
+// The hosts are converted like those of the k0sctl_config resource, so that the ssh authentication and host keys apply.
+func (hs *k0sctlSchemaModelHostsSpec) Cluster(ctx context.Context, name string) (k0sctl_v1beta1.Cluster, diag.Diagnostics) {
+	ksm := k0sctlSchemaModel{
+		Metadata: k0sctlSchemaClusterMetadata{Name: types.StringValue(name)},
+		Spec:     k0sctlSchemaModelSpec{Hosts: hs.Hosts},
+	}
+
+	c, d := ksm.Cluster(ctx)
+
+	if d.HasError() {
+		return c, d
+	}
+
+	if err := c.Validate(); err != nil {
+		d.Append(k0sctlValidationDiagnostics(err)...)
+	}
+
+	return c, d
+}
+
+// AddHostKeys settle the host keys which are only known after apply. The host keys are only recorded by the
+// k0sctl_config resource, so they are left empty.
+func (hs *k0sctlSchemaModelHostsSpec) AddHostKeys(ctx context.Context) diag.Diagnostics {
+	ksm := k0sctlSchemaModel{Spec: k0sctlSchemaModelSpec{Hosts: hs.Hosts}}
+	d := ksm.AddHostKeys(ctx)
+	hs.Hosts = ksm.Spec.Hosts
+	return d
+}
+
 type k0sctlSchemaModelSpecK0s struct {
-	Version types.String `tfsdk:"version"`
-	Config  types.String `tfsdk:"config"`
+	Version         types.String  `tfsdk:"version"`
+	Config          types.Dynamic `tfsdk:"config"`
+	VersionChannel  types.String  `tfsdk:"version_channel"`
+	VersionEndpoint types.String  `tfsdk:"version_endpoint"`
+	ResolvedVersion types.String  `tfsdk:"resolved_version"`
+	DynamicConfig   types.Bool    `tfsdk:"dynamic_config"`
+
+	ConfigSchemaEndpoint types.String `tfsdk:"config_schema_endpoint"`
 }
 
 type k0sctlSchemaModelSpecHost struct {
-	Role           types.String                     `tfsdk:"role"`
-	InstallFlags   []types.String                   `tfsdk:"install_flags"`
-	Hooks          []k0sctlSchemaModelSpecHostHooks `tfsdk:"hooks"`
-	SSH            []k0sctlSchemaModelSpecHostSSH   `tfsdk:"ssh"`
-	WinRM          []k0sctlSchemaModelSpecHostWinrm `tfsdk:"winrm"`
-	PrivateAddress types.String                     `tfsdk:"private_address"`
-	Hostname       types.String                     `tfsdk:"hostname"`
-	NoTaints       types.Bool                       `tfsdk:"no_taints"`
+	Role           types.String                         `tfsdk:"role"`
+	InstallFlags   []types.String                       `tfsdk:"install_flags"`
+	Files          []k0sctlSchemaModelSpecHostFile      `tfsdk:"files"`
+	Hooks          []k0sctlSchemaModelSpecHostHooks     `tfsdk:"hooks"`
+	SSH            []k0sctlSchemaModelSpecHostSSH       `tfsdk:"ssh"`
+	WinRM          []k0sctlSchemaModelSpecHostWinrm     `tfsdk:"winrm"`
+	OpenSSH        []k0sctlSchemaModelSpecHostOpenSSH   `tfsdk:"openssh"`
+	Localhost      []k0sctlSchemaModelSpecHostLocalhost `tfsdk:"localhost"`
+	PrivateAddress types.String                         `tfsdk:"private_address"`
+	Hostname       types.String                         `tfsdk:"hostname"`
+	NoTaints       types.Bool                           `tfsdk:"no_taints"`
+	Environment    types.Map                            `tfsdk:"environment"`
+	UploadBinary   types.Bool                           `tfsdk:"upload_binary"`
+	K0sBinaryPath  types.String                         `tfsdk:"k0s_binary_path"`
+	K0sDownloadURL types.String                         `tfsdk:"k0s_download_url"`
+}
+type k0sctlSchemaModelSpecHostFile struct {
+	Name    types.String `tfsdk:"name"`
+	Src     types.String `tfsdk:"src"`
+	Content types.String `tfsdk:"content"`
+	Dst     types.String `tfsdk:"dst"`
+	DstDir  types.String `tfsdk:"dst_dir"`
+	Perm    types.String `tfsdk:"perm"`
+	DirPerm types.String `tfsdk:"dir_perm"`
+	User    types.String `tfsdk:"user"`
+	Group   types.String `tfsdk:"group"`
 }
 type k0sctlSchemaModelSpecHostHooks struct {
-	Apply []k0sctlSchemaModelSpecHostHookAction `tfsdk:"apply"`
+	Apply  []k0sctlSchemaModelSpecHostHookAction `tfsdk:"apply"`
+	Reset  []k0sctlSchemaModelSpecHostHookAction `tfsdk:"reset"`
+	Backup []k0sctlSchemaModelSpecHostHookAction `tfsdk:"backup"`
+}
+
+// Actions the hooks of each k0sctl action.
+func (shh k0sctlSchemaModelSpecHostHooks) Actions() map[string][]k0sctlSchemaModelSpecHostHookAction {
+	return map[string][]k0sctlSchemaModelSpecHostHookAction{
+		"apply":  shh.Apply,
+		"reset":  shh.Reset,
+		"backup": shh.Backup,
+	}
 }
+
 type k0sctlSchemaModelSpecHostHookAction struct {
 	Before types.List `tfsdk:"before"`
 	After  types.List `tfsdk:"after"`
 }
 type k0sctlSchemaModelSpecHostSSH struct {
-	Address    types.String                          `tfsdk:"address"`
-	KeyPath    types.String                          `tfsdk:"key_path"`
-	KeyContent types.String                          `tfsdk:"key_content"`
-	User       types.String                          `tfsdk:"user"`
-	Port       types.Int64                           `tfsdk:"port"`
-	Bastion    []k0sctlSchemaModelSpecHostSSHBastion `tfsdk:"bastion"`
+	Address         types.String                          `tfsdk:"address"`
+	KeyPath         types.String                          `tfsdk:"key_path"`
+	KeyContent      types.String                          `tfsdk:"key_content"`
+	KeyPassphrase   types.String                          `tfsdk:"key_passphrase"`
+	UseAgent        types.Bool                            `tfsdk:"use_agent"`
+	HostKey         types.String                          `tfsdk:"host_key"`
+	TrustOnFirstUse types.Bool                            `tfsdk:"trust_on_first_use"`
+	User            types.String                          `tfsdk:"user"`
+	Port            types.Int64                           `tfsdk:"port"`
+	Bastion         []k0sctlSchemaModelSpecHostSSHBastion `tfsdk:"bastion"`
 }
 
 type k0sctlSchemaModelSpecHostSSHBastion struct {
-	Address    types.String `tfsdk:"address"`
-	KeyPath    types.String `tfsdk:"key_path"`
-	KeyContent types.String `tfsdk:"key_content"`
-	User       types.String `tfsdk:"user"`
-	Port       types.Int64  `tfsdk:"port"`
+	Address         types.String `tfsdk:"address"`
+	KeyPath         types.String `tfsdk:"key_path"`
+	KeyContent      types.String `tfsdk:"key_content"`
+	KeyPassphrase   types.String `tfsdk:"key_passphrase"`
+	UseAgent        types.Bool   `tfsdk:"use_agent"`
+	HostKey         types.String `tfsdk:"host_key"`
+	TrustOnFirstUse types.Bool   `tfsdk:"trust_on_first_use"`
+	User            types.String `tfsdk:"user"`
+	Port            types.Int64  `tfsdk:"port"`
 }
 
 type k0sctlSchemaModelSpecHostWinrm struct {
-	Address  types.String `tfsdk:"address"`
-	User     types.String `tfsdk:"user"`
-	Password types.String `tfsdk:"password"`
-	Port     types.Int64  `tfsdk:"port"`
-	UseHTTPS types.Bool   `tfsdk:"use_https"`
-	Insecure types.Bool   `tfsdk:"insecure"`
+	Address       types.String                          `tfsdk:"address"`
+	User          types.String                          `tfsdk:"user"`
+	Password      types.String                          `tfsdk:"password"`
+	Port          types.Int64                           `tfsdk:"port"`
+	UseHTTPS      types.Bool                            `tfsdk:"use_https"`
+	Insecure      types.Bool                            `tfsdk:"insecure"`
+	UseNTLM       types.Bool                            `tfsdk:"use_ntlm"`
+	CACertPath    types.String                          `tfsdk:"ca_cert_path"`
+	CertPath      types.String                          `tfsdk:"cert_path"`
+	KeyPath       types.String                          `tfsdk:"key_path"`
+	TLSServerName types.String                          `tfsdk:"tls_server_name"`
+	Bastion       []k0sctlSchemaModelSpecHostSSHBastion `tfsdk:"bastion"`
 }
+
+type k0sctlSchemaModelSpecHostOpenSSH struct {
+	Address             types.String `tfsdk:"address"`
+	User                types.String `tfsdk:"user"`
+	Port                types.Int64  `tfsdk:"port"`
+	KeyPath             types.String `tfsdk:"key_path"`
+	ConfigPath          types.String `tfsdk:"config_path"`
+	Options             types.Map    `tfsdk:"options"`
+	DisableMultiplexing types.Bool   `tfsdk:"disable_multiplexing"`
+}
+
+type k0sctlSchemaModelSpecHostLocalhost struct{}
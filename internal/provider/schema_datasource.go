@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	datasource_schema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/defaults"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// k0sctlDataSourceBlock convert a resource schema block to a data source schema block, so that the data sources share
+// the blocks of the resources (e.g. the host block).
+//
+// Plan modifiers don't apply to data sources and are dropped. Data sources have no defaults either, so attributes with
+// a default are converted as optional and computed, and the defaults are applied with k0sctlApplyDefaults.
+func k0sctlDataSourceBlock(b schema.Block) datasource_schema.Block {
+	switch b := b.(type) {
+	case schema.ListNestedBlock:
+		return datasource_schema.ListNestedBlock{
+			MarkdownDescription: b.MarkdownDescription,
+			DeprecationMessage:  b.DeprecationMessage,
+			Validators:          b.Validators,
+			NestedObject: datasource_schema.NestedBlockObject{
+				Attributes: k0sctlDataSourceAttributes(b.NestedObject.Attributes),
+				Blocks:     k0sctlDataSourceBlocks(b.NestedObject.Blocks),
+				Validators: b.NestedObject.Validators,
+			},
+		}
+	case schema.SingleNestedBlock:
+		return datasource_schema.SingleNestedBlock{
+			MarkdownDescription: b.MarkdownDescription,
+			DeprecationMessage:  b.DeprecationMessage,
+			Validators:          b.Validators,
+			Attributes:          k0sctlDataSourceAttributes(b.Attributes),
+			Blocks:              k0sctlDataSourceBlocks(b.Blocks),
+		}
+	default:
+		panic(fmt.Sprintf("k0sctl data source schema: unsupported block %T", b))
+	}
+}
+
+func k0sctlDataSourceBlocks(bs map[string]schema.Block) map[string]datasource_schema.Block {
+	dbs := make(map[string]datasource_schema.Block, len(bs))
+	for n, b := range bs {
+		dbs[n] = k0sctlDataSourceBlock(b)
+	}
+	return dbs
+}
+
+func k0sctlDataSourceAttributes(as map[string]schema.Attribute) map[string]datasource_schema.Attribute {
+	das := make(map[string]datasource_schema.Attribute, len(as))
+	for n, a := range as {
+		das[n] = k0sctlDataSourceAttribute(a)
+	}
+	return das
+}
+
+// k0sctlDataSourceAttribute convert a resource schema attribute to a data source schema attribute.
+func k0sctlDataSourceAttribute(a schema.Attribute) datasource_schema.Attribute {
+	switch a := a.(type) {
+	case schema.StringAttribute:
+		return datasource_schema.StringAttribute{
+			MarkdownDescription: a.MarkdownDescription,
+			Required:            a.Required,
+			Optional:            a.Optional,
+			Computed:            a.Computed,
+			Sensitive:           a.Sensitive,
+			DeprecationMessage:  a.DeprecationMessage,
+			Validators:          a.Validators,
+		}
+	case schema.BoolAttribute:
+		return datasource_schema.BoolAttribute{
+			MarkdownDescription: a.MarkdownDescription,
+			Required:            a.Required,
+			Optional:            a.Optional,
+			Computed:            a.Computed,
+			Sensitive:           a.Sensitive,
+			DeprecationMessage:  a.DeprecationMessage,
+			Validators:          a.Validators,
+		}
+	case schema.Int64Attribute:
+		return datasource_schema.Int64Attribute{
+			MarkdownDescription: a.MarkdownDescription,
+			Required:            a.Required,
+			Optional:            a.Optional,
+			Computed:            a.Computed,
+			Sensitive:           a.Sensitive,
+			DeprecationMessage:  a.DeprecationMessage,
+			Validators:          a.Validators,
+		}
+	case schema.ListAttribute:
+		return datasource_schema.ListAttribute{
+			MarkdownDescription: a.MarkdownDescription,
+			ElementType:         a.ElementType,
+			Required:            a.Required,
+			Optional:            a.Optional,
+			Computed:            a.Computed,
+			Sensitive:           a.Sensitive,
+			DeprecationMessage:  a.DeprecationMessage,
+			Validators:          a.Validators,
+		}
+	case schema.MapAttribute:
+		return datasource_schema.MapAttribute{
+			MarkdownDescription: a.MarkdownDescription,
+			ElementType:         a.ElementType,
+			Required:            a.Required,
+			Optional:            a.Optional,
+			Computed:            a.Computed,
+			Sensitive:           a.Sensitive,
+			DeprecationMessage:  a.DeprecationMessage,
+			Validators:          a.Validators,
+		}
+	default:
+		panic(fmt.Sprintf("k0sctl data source schema: unsupported attribute %T", a))
+	}
+}
+
+// k0sctlApplyDefaults set the null attributes of a data source configuration which have a default in the resource
+// schema s to the default, as terraform does for resources.
+//
+// The resource schema has the blocks which the data source shares, attributes which are not in it are left as is.
+func k0sctlApplyDefaults(ctx context.Context, s schema.Schema, raw tftypes.Value) (tftypes.Value, error) {
+	return tftypes.Transform(raw, func(p *tftypes.AttributePath, v tftypes.Value) (tftypes.Value, error) {
+		if !v.IsNull() || len(p.Steps()) == 0 {
+			return v, nil
+		}
+
+		a, err := s.AttributeAtTerraformPath(ctx, p)
+		if err != nil {
+			// not a shared attribute
+			return v, nil
+		}
+
+		switch a := a.(type) {
+		case schema.StringAttribute:
+			if a.Default != nil {
+				resp := defaults.StringResponse{}
+				a.Default.DefaultString(ctx, defaults.StringRequest{}, &resp)
+				return tftypes.NewValue(tftypes.String, resp.PlanValue.ValueStringPointer()), nil
+			}
+		case schema.BoolAttribute:
+			if a.Default != nil {
+				resp := defaults.BoolResponse{}
+				a.Default.DefaultBool(ctx, defaults.BoolRequest{}, &resp)
+				return tftypes.NewValue(tftypes.Bool, resp.PlanValue.ValueBoolPointer()), nil
+			}
+		case schema.Int64Attribute:
+			if a.Default != nil {
+				resp := defaults.Int64Response{}
+				a.Default.DefaultInt64(ctx, defaults.Int64Request{}, &resp)
+				return tftypes.NewValue(tftypes.Number, resp.PlanValue.ValueInt64Pointer()), nil
+			}
+		}
+
+		return v, nil
+	})
+}
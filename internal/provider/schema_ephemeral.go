@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"fmt"
+
+	ephemeral_schema "github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+)
+
+// k0sctlEphemeralBlock convert a resource schema block to an ephemeral resource schema block, as k0sctlDataSourceBlock
+// does for the data sources.
+func k0sctlEphemeralBlock(b schema.Block) ephemeral_schema.Block {
+	switch b := b.(type) {
+	case schema.ListNestedBlock:
+		return ephemeral_schema.ListNestedBlock{
+			MarkdownDescription: b.MarkdownDescription,
+			DeprecationMessage:  b.DeprecationMessage,
+			Validators:          b.Validators,
+			NestedObject: ephemeral_schema.NestedBlockObject{
+				Attributes: k0sctlEphemeralAttributes(b.NestedObject.Attributes),
+				Blocks:     k0sctlEphemeralBlocks(b.NestedObject.Blocks),
+				Validators: b.NestedObject.Validators,
+			},
+		}
+	case schema.SingleNestedBlock:
+		return ephemeral_schema.SingleNestedBlock{
+			MarkdownDescription: b.MarkdownDescription,
+			DeprecationMessage:  b.DeprecationMessage,
+			Validators:          b.Validators,
+			Attributes:          k0sctlEphemeralAttributes(b.Attributes),
+			Blocks:              k0sctlEphemeralBlocks(b.Blocks),
+		}
+	default:
+		panic(fmt.Sprintf("k0sctl ephemeral resource schema: unsupported block %T", b))
+	}
+}
+
+func k0sctlEphemeralBlocks(bs map[string]schema.Block) map[string]ephemeral_schema.Block {
+	dbs := make(map[string]ephemeral_schema.Block, len(bs))
+	for n, b := range bs {
+		dbs[n] = k0sctlEphemeralBlock(b)
+	}
+	return dbs
+}
+
+func k0sctlEphemeralAttributes(as map[string]schema.Attribute) map[string]ephemeral_schema.Attribute {
+	das := make(map[string]ephemeral_schema.Attribute, len(as))
+	for n, a := range as {
+		das[n] = k0sctlEphemeralAttribute(a)
+	}
+	return das
+}
+
+// k0sctlEphemeralAttribute convert a resource schema attribute to an ephemeral resource schema attribute.
+func k0sctlEphemeralAttribute(a schema.Attribute) ephemeral_schema.Attribute {
+	switch a := a.(type) {
+	case schema.StringAttribute:
+		return ephemeral_schema.StringAttribute{
+			MarkdownDescription: a.MarkdownDescription,
+			Required:            a.Required,
+			Optional:            a.Optional,
+			Computed:            a.Computed,
+			Sensitive:           a.Sensitive,
+			DeprecationMessage:  a.DeprecationMessage,
+			Validators:          a.Validators,
+		}
+	case schema.BoolAttribute:
+		return ephemeral_schema.BoolAttribute{
+			MarkdownDescription: a.MarkdownDescription,
+			Required:            a.Required,
+			Optional:            a.Optional,
+			Computed:            a.Computed,
+			Sensitive:           a.Sensitive,
+			DeprecationMessage:  a.DeprecationMessage,
+			Validators:          a.Validators,
+		}
+	case schema.Int64Attribute:
+		return ephemeral_schema.Int64Attribute{
+			MarkdownDescription: a.MarkdownDescription,
+			Required:            a.Required,
+			Optional:            a.Optional,
+			Computed:            a.Computed,
+			Sensitive:           a.Sensitive,
+			DeprecationMessage:  a.DeprecationMessage,
+			Validators:          a.Validators,
+		}
+	case schema.ListAttribute:
+		return ephemeral_schema.ListAttribute{
+			MarkdownDescription: a.MarkdownDescription,
+			ElementType:         a.ElementType,
+			Required:            a.Required,
+			Optional:            a.Optional,
+			Computed:            a.Computed,
+			Sensitive:           a.Sensitive,
+			DeprecationMessage:  a.DeprecationMessage,
+			Validators:          a.Validators,
+		}
+	case schema.MapAttribute:
+		return ephemeral_schema.MapAttribute{
+			MarkdownDescription: a.MarkdownDescription,
+			ElementType:         a.ElementType,
+			Required:            a.Required,
+			Optional:            a.Optional,
+			Computed:            a.Computed,
+			Sensitive:           a.Sensitive,
+			DeprecationMessage:  a.DeprecationMessage,
+			Validators:          a.Validators,
+		}
+	default:
+		panic(fmt.Sprintf("k0sctl ephemeral resource schema: unsupported attribute %T", a))
+	}
+}
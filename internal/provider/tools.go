@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/k0sproject/rig"
 	"github.com/sirupsen/logrus"
@@ -110,3 +111,11 @@ func helperStringBase64Decode(val string) string {
 	valDecodedBytes, _ := base64.StdEncoding.DecodeString(val)
 	return string(valDecodedBytes)
 }
+
+// helperStringValueOrNull a terraform string value, null if the string is empty.
+func helperStringValueOrNull(val string) types.String {
+	if val == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(val)
+}
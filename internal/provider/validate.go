@@ -0,0 +1,206 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/jellydator/validation"
+)
+
+// k0sctl yaml keys which don't convert directly from camelcase to the schema attribute name.
+var k0sctlYamlKeyAttributeNames = map[string]string{
+	"hosts":          "host",
+	"winRM":          "winrm",
+	"useHTTPS":       "use_https",
+	"useNTLM":        "use_ntlm",
+	"openSSH":        "openssh",
+	"data":           "content",
+	"k0sDownloadURL": "k0s_download_url",
+}
+
+// k0sctlValidationDiagnostics convert a k0sctl Cluster.Validate() error into diagnostics.
+//
+// k0sctl validation errors are nested maps keyed by the k0sctl yaml keys, which are converted to schema
+// attribute paths so that each diagnostic points at the offending attribute.
+func k0sctlValidationDiagnostics(err error) diag.Diagnostics {
+	d := diag.Diagnostics{}
+	k0sctlValidationErrorWalk(err, []string{}, &d)
+	return d
+}
+
+func k0sctlValidationErrorWalk(err error, yp []string, d *diag.Diagnostics) {
+	var ves validation.Errors
+
+	if errors.As(err, &ves) {
+		keys := make([]string, 0, len(ves))
+		for k := range ves {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			kyp := append(append([]string{}, yp...), k)
+			k0sctlValidationErrorWalk(ves[k], kyp, d)
+		}
+		return
+	}
+
+	if len(yp) == 0 {
+		d.AddError("k0sctl cluster validation failed", err.Error())
+		return
+	}
+
+	ap := k0sctlYamlPathToAttributePath(yp)
+	mes := fmt.Sprintf("%s: %s", strings.Join(yp, "."), err.Error())
+
+	if ap.Equal(path.Empty()) {
+		d.AddError("k0sctl cluster validation failed", mes)
+	} else {
+		d.AddAttributeError(ap, "k0sctl cluster validation failed", mes)
+	}
+}
+
+// k0sctlYamlPathToAttributePath convert a path of k0sctl yaml keys to the matching schema attribute path.
+//
+// The schema is followed as far as the yaml path matches it, so an error on a k0sctl field which the schema
+// does not expose points at the closest parent.
+func k0sctlYamlPathToAttributePath(yp []string) path.Path {
+	s := k0sctl_v1beta1_schema()
+
+	p := path.Empty()
+	attrs, blocks := s.Attributes, s.Blocks
+
+	for i := 0; i < len(yp); i++ {
+		name := k0sctlYamlKeyToAttributeName(yp[i])
+
+		if _, ok := attrs[name]; ok {
+			return p.AtName(name)
+		}
+
+		b, ok := blocks[name]
+		if !ok {
+			return p
+		}
+
+		p = p.AtName(name)
+
+		switch tb := b.(type) {
+		case schema.SingleNestedBlock:
+			attrs, blocks = tb.Attributes, tb.Blocks
+		case schema.ListNestedBlock:
+			attrs, blocks = tb.NestedObject.Attributes, tb.NestedObject.Blocks
+
+			if i+1 < len(yp) {
+				if idx, err := strconv.Atoi(yp[i+1]); err == nil {
+					p = p.AtListIndex(idx)
+					i++
+					continue
+				}
+			}
+			// a single k0sctl object, which the schema models as a list block with one item
+			p = p.AtListIndex(0)
+		default:
+			return p
+		}
+	}
+
+	return p
+}
+
+// k0sctlYamlKeyToAttributeName convert a camelcase k0sctl yaml key to a snakecase schema attribute name.
+func k0sctlYamlKeyToAttributeName(key string) string {
+	if name, ok := k0sctlYamlKeyAttributeNames[key]; ok {
+		return name
+	}
+
+	var sb strings.Builder
+	for _, r := range key {
+		if unicode.IsUpper(r) {
+			sb.WriteRune('_')
+			sb.WriteRune(unicode.ToLower(r))
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// k0sctlSchemaModelFromKnown read a config or plan, which may hold values that are only known after apply, into
+// the model.
+//
+// The model holds blocks and some lists as go slices, which can't be unknown, so unknown lists (e.g. dynamic blocks
+// over values known after apply) are read as null lists.
+func k0sctlSchemaModelFromKnown(ctx context.Context, raw tftypes.Value, ksm *k0sctlSchemaModel) diag.Diagnostics {
+	d := diag.Diagnostics{}
+
+	kraw, err := tftypes.Transform(raw, func(_ *tftypes.AttributePath, v tftypes.Value) (tftypes.Value, error) {
+		if !v.IsKnown() && (v.Type().Is(tftypes.List{}) || v.Type().Is(tftypes.Set{})) {
+			return tftypes.NewValue(v.Type(), nil), nil
+		}
+		return v, nil
+	})
+	if err != nil {
+		d.AddError("Could not read the k0sctl config", err.Error())
+		return d
+	}
+
+	d.Append(tfsdk.Config{Raw: kraw, Schema: k0sctl_v1beta1_schema()}.Get(ctx, ksm)...)
+	return d
+}
+
+// k0sctlKnownDiagnostics the diagnostics which point at values that are known in a config or plan.
+//
+// A diagnostic about a value which is only known after apply, or about the cluster as a whole when some of it is
+// only known after apply, may not hold once the values are known.
+func k0sctlKnownDiagnostics(raw tftypes.Value, ds diag.Diagnostics) diag.Diagnostics {
+	if raw.IsFullyKnown() {
+		return ds
+	}
+
+	d := diag.Diagnostics{}
+
+	for _, di := range ds {
+		dp, ok := di.(diag.DiagnosticWithPath)
+		if !ok {
+			continue
+		}
+
+		if v, _, err := tftypes.WalkAttributePath(raw, k0sctlAttributePath(dp.Path())); err != nil {
+			continue
+		} else if tv, ok := v.(tftypes.Value); !ok || !tv.IsFullyKnown() {
+			continue
+		}
+
+		d.Append(di)
+	}
+
+	return d
+}
+
+// k0sctlAttributePath convert a framework path into a terraform attribute path.
+func k0sctlAttributePath(p path.Path) *tftypes.AttributePath {
+	tp := tftypes.NewAttributePath()
+
+	for _, ps := range p.Steps() {
+		switch s := ps.(type) {
+		case path.PathStepAttributeName:
+			tp = tp.WithAttributeName(string(s))
+		case path.PathStepElementKeyInt:
+			tp = tp.WithElementKeyInt(int(s))
+		case path.PathStepElementKeyString:
+			tp = tp.WithElementKeyString(string(s))
+		}
+	}
+
+	return tp
+}
@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/jellydator/validation"
+)
+
+func TestK0sctlYamlPathToAttributePath(t *testing.T) {
+	hp := path.Root("spec").AtName("host")
+
+	for _, c := range []struct {
+		yp   []string
+		want path.Path
+	}{
+		{yp: []string{"metadata", "name"}, want: path.Root("metadata").AtName("name")},
+		{yp: []string{"spec", "k0s", "version"}, want: path.Root("spec").AtName("k0s").AtName("version")},
+		{yp: []string{"spec", "hosts", "1", "role"}, want: hp.AtListIndex(1).AtName("role")},
+		{yp: []string{"spec", "hosts", "1", "ssh", "address"}, want: hp.AtListIndex(1).AtName("ssh").AtListIndex(0).AtName("address")},
+		{yp: []string{"spec", "hosts", "0", "winRM", "useHTTPS"}, want: hp.AtListIndex(0).AtName("winrm").AtListIndex(0).AtName("use_https")},
+		{yp: []string{"spec", "hosts", "2", "openSSH", "keyPath"}, want: hp.AtListIndex(2).AtName("openssh").AtListIndex(0).AtName("key_path")},
+		{yp: []string{"spec", "hosts", "0", "files", "1", "data"}, want: hp.AtListIndex(0).AtName("files").AtListIndex(1).AtName("content")},
+		{yp: []string{"spec", "hosts", "0", "k0sDownloadURL"}, want: hp.AtListIndex(0).AtName("k0s_download_url")},
+		// k0sctl fields which the schema does not expose point at the closest parent
+		{yp: []string{"spec", "hosts", "3", "os"}, want: hp.AtListIndex(3)},
+		{yp: []string{"apiVersion"}, want: path.Empty()},
+	} {
+		if got := k0sctlYamlPathToAttributePath(c.yp); !got.Equal(c.want) {
+			t.Errorf("%v converted to %s, expected %s", c.yp, got, c.want)
+		}
+	}
+}
+
+func TestK0sctlValidationDiagnostics(t *testing.T) {
+	err := validation.Errors{
+		"spec": validation.Errors{
+			"hosts": validation.Errors{
+				"0": validation.Errors{
+					"ssh": validation.Errors{
+						"address": errors.New("cannot be blank"),
+					},
+				},
+			},
+		},
+		"apiVersion": errors.New("must be k0sctl.k0sproject.io/v1beta1"),
+	}
+
+	d := k0sctlValidationDiagnostics(err)
+	if len(d) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %v", d)
+	}
+
+	// the keys are sorted, so that the diagnostics are stable
+	if _, ok := d[0].(diag.DiagnosticWithPath); ok {
+		t.Errorf("the apiVersion error has a path: %v", d[0])
+	}
+	if dp, ok := d[1].(diag.DiagnosticWithPath); !ok || !dp.Path().Equal(path.Root("spec").AtName("host").AtListIndex(0).AtName("ssh").AtListIndex(0).AtName("address")) {
+		t.Errorf("the address error does not point at the address: %v", d[1])
+	}
+
+	if d := k0sctlValidationDiagnostics(errors.New("no controllers")); len(d) != 1 || d[0].Detail() != "no controllers" {
+		t.Errorf("a plain error was not reported: %v", d)
+	}
+}
+
+func TestK0sctlSchemaModelFromKnown(t *testing.T) {
+	ctx := context.Background()
+	s := k0sctl_v1beta1_schema()
+
+	hostsType, d := s.TypeAtPath(ctx, path.Root("spec").AtName("host"))
+	if d.HasError() {
+		t.Fatal(d)
+	}
+
+	// the hosts are a dynamic block over values which are only known after apply
+	config := tfsdk.Config{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(ctx), nil)}
+	plan := tfsdk.Plan(config)
+	for _, sv := range []struct {
+		p path.Path
+		v attr.Value
+	}{
+		{p: path.Root("metadata").AtName("name"), v: types.StringValue("test")},
+		{p: path.Root("spec").AtName("k0s").AtName("version"), v: types.StringValue("v1.30.2+k0s.0")},
+		{p: path.Root("spec").AtName("host"), v: types.ListUnknown(hostsType.(types.ListType).ElemType)},
+	} {
+		if d := plan.SetAttribute(ctx, sv.p, sv.v); d.HasError() {
+			t.Fatal(d)
+		}
+	}
+	config.Raw = plan.Raw
+
+	var ksm k0sctlSchemaModel
+	if d := config.Get(ctx, &ksm); !d.HasError() {
+		t.Error("expected the unknown hosts to fail a plain Get, the test no longer covers anything")
+	}
+
+	if d := k0sctlSchemaModelFromKnown(ctx, config.Raw, &ksm); d.HasError() {
+		t.Fatalf("the config could not be read: %v", d)
+	}
+	if len(ksm.Spec.Hosts) != 0 || ksm.Spec.K0s.Version.ValueString() != "v1.30.2+k0s.0" {
+		t.Errorf("the known values were not read: %+v", ksm.Spec)
+	}
+
+	ds := diag.Diagnostics{}
+	ds.AddError("no controllers", "the cluster has no controllers")
+	ds.AddAttributeError(path.Root("spec").AtName("host").AtListIndex(0).AtName("role"), "invalid role", "invalid role")
+	ds.AddAttributeError(path.Root("spec").AtName("k0s").AtName("version"), "invalid version", "invalid version")
+
+	kd := k0sctlKnownDiagnostics(config.Raw, ds)
+	if len(kd) != 1 || kd[0].Summary() != "invalid version" {
+		t.Errorf("only the diagnostic about the known version should be kept, got %v", kd)
+	}
+}